@@ -0,0 +1,68 @@
+//go:build !wasm
+
+package aws
+
+import (
+	"testing"
+
+	pdk "github.com/extism/go-pdk"
+	"github.com/extism/go-pdk/native"
+)
+
+func TestSign(t *testing.T) {
+	native.Configure(native.Options{Config: map[string]string{
+		AccessKeyIDConfigKey:     "AKIDEXAMPLE",
+		SecretAccessKeyConfigKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}})
+	defer native.Configure(native.Options{})
+
+	native.Call(nil, func() int32 {
+		creds, err := CredentialsFromConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the get-vanilla request of the AWS SigV4 test suite
+		req := pdk.NewHTTPRequest(pdk.MethodGet, "https://example.amazonaws.com/")
+		req.SetHeader("X-Amz-Date", "20150830T123600Z")
+		if err := Sign(req, creds, "us-east-1", "service"); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+			"SignedHeaders=host;x-amz-date, " +
+			"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+		if got := req.Meta().Headers["Authorization"]; got != want {
+			t.Errorf("Authorization\ngot  %s\nwant %s", got, want)
+		}
+		return 0
+	})
+}
+
+func TestCredentialsFromConfig(t *testing.T) {
+	native.Configure(native.Options{Config: map[string]string{
+		AccessKeyIDConfigKey:  "id",
+		SessionTokenConfigKey: "token",
+	}})
+	defer native.Configure(native.Options{})
+
+	native.Call(nil, func() int32 {
+		if _, err := CredentialsFromConfig(); err == nil {
+			t.Error("CredentialsFromConfig() without a secret key succeeded")
+		}
+		return 0
+	})
+
+	native.Configure(native.Options{Config: map[string]string{
+		AccessKeyIDConfigKey:     "id",
+		SecretAccessKeyConfigKey: "secret",
+		SessionTokenConfigKey:    "token",
+	}})
+	native.Call(nil, func() int32 {
+		creds, err := CredentialsFromConfig()
+		if want := (Credentials{AccessKeyID: "id", SecretAccessKey: "secret", SessionToken: "token"}); err != nil || creds != want {
+			t.Errorf("CredentialsFromConfig() = %+v, %v, want %+v", creds, err, want)
+		}
+		return 0
+	})
+}
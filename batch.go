@@ -0,0 +1,133 @@
+package pdk
+
+import (
+	"strconv"
+
+	"github.com/extism/go-pdk/jsonlite"
+)
+
+// FailurePolicy decides whether a `Batch` export fails, from the number of items that failed.
+type FailurePolicy int
+
+const (
+	// FailOnAll makes the export fail if every item failed, and there was at least one.
+	FailOnAll FailurePolicy = iota
+	// FailOnAny makes the export fail if any item failed.
+	FailOnAny
+	// FailNever never makes the export fail because of failed items, which the host then only
+	// finds in the output.
+	FailNever
+)
+
+func (p FailurePolicy) fails(failed, total int) bool {
+	switch p {
+	case FailOnAny:
+		return failed > 0
+	case FailNever:
+		return false
+	default:
+		return failed > 0 && failed == total
+	}
+}
+
+// Batch runs `fn` on each item of the input, a JSON array, and outputs the result of each item
+// along with summary counts, with the `FailOnAll` policy (see `BatchWith`):
+//
+//	//go:wasmexport greet_all
+//	func greetAll() int32 {
+//		return pdk.Batch(func(name string) (string, error) {
+//			if name == "" {
+//				return "", errors.New("empty name")
+//			}
+//			return "Hello, " + name + "!", nil
+//		})
+//	}
+func Batch[In, Out any](fn func(In) (Out, error)) int32 {
+	return BatchWith(fn, FailOnAll)
+}
+
+// BatchWith is like `Batch`, failing the export according to `policy`.
+//
+// An item fails if it cannot be decoded into an `In`, if `fn` returns an error or panics, or if
+// its result cannot be encoded. The other items are not affected, and the output holds an entry
+// for each item in order, with either its result or its error message:
+//
+//	{"results":[{"result":"Hello, Joe!"},{"error":"empty name"}],"succeeded":1,"failed":1}
+//
+// The output is sent even if the export fails, along with an error giving the number of failed
+// items. An input that is not a JSON array fails the export without any output.
+func BatchWith[In, Out any](fn func(In) (Out, error), policy FailurePolicy) int32 {
+	data := Input()
+	var items [][]byte
+	d := jsonlite.NewDecoder(data)
+	err := d.Array(func() error {
+		item, err := d.Raw()
+		items = append(items, item)
+		return err
+	})
+	if err == nil {
+		err = d.End()
+	}
+	if err != nil {
+		SetError(&DecodeError{Type: "JSON array", Length: len(data), Err: err})
+		return 1
+	}
+
+	failed := 0
+	e := jsonlite.NewEncoder()
+	e.BeginObject()
+	e.Key("results")
+	e.BeginArray()
+	for _, item := range items {
+		result, err := batchItem(fn, item)
+		e.BeginObject()
+		if err != nil {
+			failed++
+			e.Key("error")
+			e.String(err.Error())
+		} else {
+			e.Key("result")
+			e.Raw(result)
+		}
+		e.EndObject()
+	}
+	e.EndArray()
+	e.Key("succeeded")
+	e.Int(int64(len(items) - failed))
+	e.Key("failed")
+	e.Int(int64(failed))
+	e.EndObject()
+
+	output, err := e.Bytes()
+	if err != nil {
+		SetError(err)
+		return 1
+	}
+	Output(output)
+
+	if policy.fails(failed, len(items)) {
+		SetErrorString(strconv.Itoa(failed) + " of " + strconv.Itoa(len(items)) + " items failed")
+		return 1
+	}
+	return 0
+}
+
+// batchItem decodes `item`, runs `fn` on it and returns its encoded result, turning a panic into
+// an error.
+func batchItem[In, Out any](fn func(In) (Out, error), item []byte) (result []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, panicError(r)
+		}
+	}()
+
+	var in In
+	if err := unmarshalJSON(item, &in); err != nil {
+		return nil, &DecodeError{Type: "JSON", Length: len(item), Err: err}
+	}
+	out, err := fn(in)
+	if err != nil {
+		return nil, err
+	}
+	return marshalJSON(out)
+}
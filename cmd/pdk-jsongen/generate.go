@@ -0,0 +1,495 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const jsonliteImport = "github.com/extism/go-pdk/jsonlite"
+
+// kind is how a Go type is encoded.
+type kind int
+
+const (
+	kindString kind = iota
+	kindBool
+	kindInt
+	kindUint
+	kindFloat
+	kindBytes
+	kindPointer
+	kindSlice
+	kindMap
+	// kindMarshaler is a struct type of the package, which gets generated methods, or a type from
+	// another package, which must implement them.
+	kindMarshaler
+)
+
+// goType is a resolved Go type.
+type goType struct {
+	kind kind
+	// expr is the type as written in Go, e.g. "[]*Item".
+	expr string
+	// basic is the underlying type of scalar kinds, e.g. "int32"; a named scalar type needs
+	// converting from and to it.
+	basic string
+	named bool
+	elem  *goType
+	// imports holds the paths of the packages `expr` refers to.
+	imports []string
+}
+
+// field is an encoded struct field.
+type field struct {
+	goName    string
+	jsonName  string
+	omitEmpty bool
+	typ       *goType
+}
+
+// Generator produces the JSON methods of the struct types of a `Package`.
+type Generator struct {
+	pkg *Package
+
+	buf     bytes.Buffer
+	imports map[string]bool
+	// pending holds the struct types left to generate, and queued the ones ever added to it.
+	pending []string
+	queued  map[string]bool
+	// current is the type being generated, whose file resolves package names.
+	current string
+}
+
+// NewGenerator returns a `Generator` for `pkg`.
+func NewGenerator(pkg *Package) *Generator {
+	return &Generator{pkg: pkg}
+}
+
+// Generate returns the source of the methods of the struct types `names`, and of the struct types
+// they reference. An empty `names` selects every struct type of the package.
+func (g *Generator) Generate(names []string) ([]byte, error) {
+	g.buf.Reset()
+	g.imports = map[string]bool{jsonliteImport: true}
+	g.pending = nil
+	g.queued = map[string]bool{}
+
+	if len(names) == 0 {
+		names = g.pkg.structTypes()
+	}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		spec, ok := g.pkg.types[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found", name)
+		}
+		if _, ok := spec.Type.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		g.queue(name)
+	}
+
+	for len(g.pending) > 0 {
+		name := g.pending[0]
+		g.pending = g.pending[1:]
+		if err := g.structType(name); err != nil {
+			return nil, fmt.Errorf("type %s: %w", name, err)
+		}
+	}
+
+	return g.source()
+}
+
+func (g *Generator) queue(name string) {
+	if !g.queued[name] {
+		g.queued[name] = true
+		g.pending = append(g.pending, name)
+	}
+}
+
+func (g *Generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *Generator) source() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString("// Code generated by pdk-jsongen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg.Name)
+
+	var std, other []string
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if strings.Contains(path, ".") {
+			other = append(other, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+
+	out.WriteString("import (\n")
+	for _, path := range std {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	if len(std) > 0 {
+		out.WriteString("\n")
+	}
+	for _, path := range other {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n\n")
+
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *Generator) structType(name string) error {
+	g.current = name
+	spec := g.pkg.types[name]
+	if spec.TypeParams != nil {
+		return fmt.Errorf("generic types are not supported")
+	}
+
+	fields, err := g.fields(spec.Type.(*ast.StructType))
+	if err != nil {
+		return err
+	}
+
+	methods := g.pkg.methods[name]
+	if !methods["MarshalJSON"] {
+		g.marshal(name, fields)
+	}
+	if !methods["UnmarshalJSON"] {
+		g.unmarshal(name, fields)
+	}
+	return nil
+}
+
+func (g *Generator) fields(st *ast.StructType) ([]field, error) {
+	var fields []field
+	for _, f := range st.Fields.List {
+		tag := ""
+		if f.Tag != nil {
+			unquoted, _ := strconv.Unquote(f.Tag.Value)
+			tag = reflect.StructTag(unquoted).Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("embedded field %s is not supported", types.ExprString(f.Type))
+		}
+
+		jsonName, options, _ := strings.Cut(tag, ",")
+		omitEmpty := false
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "":
+			case "omitempty":
+				omitEmpty = true
+			default:
+				return nil, fmt.Errorf("json tag option %q is not supported", option)
+			}
+		}
+
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+			typ, err := g.resolve(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", ident.Name, err)
+			}
+			name := jsonName
+			if name == "" {
+				name = ident.Name
+			}
+			fields = append(fields, field{goName: ident.Name, jsonName: name, omitEmpty: omitEmpty, typ: typ})
+		}
+	}
+	return fields, nil
+}
+
+var basicKinds = map[string]kind{
+	"string": kindString, "bool": kindBool,
+	"int": kindInt, "int8": kindInt, "int16": kindInt, "int32": kindInt, "int64": kindInt, "rune": kindInt,
+	"uint": kindUint, "uint8": kindUint, "uint16": kindUint, "uint32": kindUint, "uint64": kindUint,
+	"uintptr": kindUint, "byte": kindUint,
+	"float32": kindFloat, "float64": kindFloat,
+}
+
+// resolve returns how the type `expr` is encoded.
+func (g *Generator) resolve(expr ast.Expr) (*goType, error) {
+	text := types.ExprString(expr)
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		if k, ok := basicKinds[expr.Name]; ok {
+			return &goType{kind: k, expr: text, basic: expr.Name}, nil
+		}
+		spec, ok := g.pkg.types[expr.Name]
+		if !ok {
+			return nil, fmt.Errorf("type %s is not supported", expr.Name)
+		}
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			if spec.TypeParams != nil {
+				return nil, fmt.Errorf("generic type %s is not supported", expr.Name)
+			}
+			g.queue(expr.Name)
+			return &goType{kind: kindMarshaler, expr: text}, nil
+		}
+		if methods := g.pkg.methods[expr.Name]; methods["MarshalJSON"] && methods["UnmarshalJSON"] {
+			return &goType{kind: kindMarshaler, expr: text}, nil
+		}
+		underlying, err := g.resolve(spec.Type)
+		if err != nil {
+			return nil, err
+		}
+		named := *underlying
+		named.expr = text
+		named.named = true
+		named.imports = nil
+		return &named, nil
+	case *ast.StarExpr:
+		elem, err := g.resolve(expr.X)
+		if err != nil {
+			return nil, err
+		}
+		return &goType{kind: kindPointer, expr: text, elem: elem, imports: elem.imports}, nil
+	case *ast.ArrayType:
+		if expr.Len != nil {
+			return nil, fmt.Errorf("array type %s is not supported", text)
+		}
+		elem, err := g.resolve(expr.Elt)
+		if err != nil {
+			return nil, err
+		}
+		if elem.kind == kindUint && !elem.named && (elem.basic == "byte" || elem.basic == "uint8") {
+			return &goType{kind: kindBytes, expr: text}, nil
+		}
+		return &goType{kind: kindSlice, expr: text, elem: elem, imports: elem.imports}, nil
+	case *ast.MapType:
+		key, err := g.resolve(expr.Key)
+		if err != nil {
+			return nil, err
+		}
+		if key.kind != kindString {
+			return nil, fmt.Errorf("map type %s is not supported, keys must be strings", text)
+		}
+		elem, err := g.resolve(expr.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &goType{kind: kindMap, expr: text, elem: elem, imports: elem.imports}, nil
+	case *ast.SelectorExpr:
+		pkg, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not supported", text)
+		}
+		path, err := g.pkg.importPath(g.current, pkg.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &goType{kind: kindMarshaler, expr: text, imports: []string{path}}, nil
+	case *ast.ParenExpr:
+		return g.resolve(expr.X)
+	default:
+		return nil, fmt.Errorf("type %s is not supported", text)
+	}
+}
+
+func (g *Generator) marshal(name string, fields []field) {
+	g.printf("// MarshalJSON encodes a `%s` as JSON, without reflection.\n", name)
+	g.printf("func (v %s) MarshalJSON() ([]byte, error) {\n", name)
+	g.printf("e := jsonlite.NewEncoder()\n")
+	g.printf("e.BeginObject()\n")
+	for _, f := range fields {
+		value := "v." + f.goName
+		if f.omitEmpty {
+			if cond := nonEmpty(value, f.typ); cond != "" {
+				g.printf("if %s {\n", cond)
+				g.printf("e.Key(%q)\n", f.jsonName)
+				g.encodeNonNil(value, f.typ, 0)
+				g.printf("}\n")
+				continue
+			}
+		}
+		g.printf("e.Key(%q)\n", f.jsonName)
+		g.encode(value, f.typ, 0)
+	}
+	g.printf("e.EndObject()\n")
+	g.printf("return e.Bytes()\n")
+	g.printf("}\n\n")
+}
+
+// nonEmpty returns the condition under which `value` is not empty, in the sense of omitempty, or
+// "" if it never is.
+func nonEmpty(value string, t *goType) string {
+	switch t.kind {
+	case kindString:
+		return value + ` != ""`
+	case kindBool:
+		return value
+	case kindInt, kindUint, kindFloat:
+		return value + " != 0"
+	case kindPointer:
+		return value + " != nil"
+	case kindBytes, kindSlice, kindMap:
+		return "len(" + value + ") != 0"
+	default:
+		return ""
+	}
+}
+
+// convert returns `value` converted to `basic`, if its type is named or different.
+func convert(basic, value string, t *goType) string {
+	if !t.named && t.basic == basic {
+		return value
+	}
+	return basic + "(" + value + ")"
+}
+
+// encode writes the statements encoding `value`, of type `t`, with the encoder `e`. `depth`
+// numbers the variables of nested slices and maps.
+func (g *Generator) encode(value string, t *goType, depth int) {
+	switch t.kind {
+	case kindString:
+		g.printf("e.String(%s)\n", convert("string", value, t))
+	case kindBool:
+		g.printf("e.Bool(%s)\n", convert("bool", value, t))
+	case kindInt:
+		g.printf("e.Int(%s)\n", convert("int64", value, t))
+	case kindUint:
+		g.printf("e.Uint(%s)\n", convert("uint64", value, t))
+	case kindFloat:
+		bits := 64
+		if t.basic == "float32" {
+			bits = 32
+		}
+		g.printf("e.Float(%s, %d)\n", convert("float64", value, t), bits)
+	case kindBytes:
+		if t.named {
+			value = "[]byte(" + value + ")"
+		}
+		g.printf("e.ByteSlice(%s)\n", value)
+	case kindMarshaler:
+		g.printf("e.Marshaler(&%s)\n", value)
+	case kindPointer, kindSlice, kindMap:
+		g.printf("if %s == nil {\n", value)
+		g.printf("e.Null()\n")
+		g.printf("} else {\n")
+		g.encodeNonNil(value, t, depth)
+		g.printf("}\n")
+	default:
+		g.encodeNonNil(value, t, depth)
+	}
+}
+
+// encodeNonNil is like encode, for a `value` known not to be nil.
+func (g *Generator) encodeNonNil(value string, t *goType, depth int) {
+	switch t.kind {
+	case kindPointer:
+		if t.elem.kind == kindMarshaler {
+			g.printf("e.Marshaler(%s)\n", value)
+		} else {
+			g.encode("*"+value, t.elem, depth)
+		}
+	case kindSlice:
+		item := "item" + strconv.Itoa(depth)
+		g.printf("e.BeginArray()\n")
+		g.printf("for _, %s := range %s {\n", item, value)
+		g.encode(item, t.elem, depth+1)
+		g.printf("}\n")
+		g.printf("e.EndArray()\n")
+	case kindMap:
+		key := "key" + strconv.Itoa(depth)
+		item := "item" + strconv.Itoa(depth)
+		g.printf("e.BeginObject()\n")
+		g.printf("for _, %s := range jsonlite.SortedKeys(%s) {\n", key, value)
+		g.printf("%s := %s[%s]\n", item, value, key)
+		g.printf("e.Key(string(%s))\n", key)
+		g.encode(item, t.elem, depth+1)
+		g.printf("}\n")
+		g.printf("e.EndObject()\n")
+	default:
+		g.encode(value, t, depth)
+	}
+}
+
+func (g *Generator) unmarshal(name string, fields []field) {
+	g.printf("// UnmarshalJSON decodes a `%s` from JSON, without reflection.\n", name)
+	g.printf("func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+	g.printf("d := jsonlite.NewDecoder(data)\n")
+	g.printf("if d.Null() {\n")
+	g.printf("return d.End()\n")
+	g.printf("}\n")
+
+	if len(fields) == 0 {
+		g.printf("err := d.Object(func(string) error {\n")
+		g.printf("return d.Skip()\n")
+	} else {
+		names := make([]string, len(fields))
+		for i, f := range fields {
+			names[i] = strconv.Quote(f.jsonName)
+		}
+		g.printf("err := d.Object(func(key string) error {\n")
+		g.printf("switch jsonlite.Field(key, %s) {\n", strings.Join(names, ", "))
+		for _, f := range fields {
+			g.printf("case %q:\n", f.jsonName)
+			g.printf("return %s\n", g.decode("&v."+f.goName, f.typ, 0))
+		}
+		g.printf("default:\n")
+		g.printf("return d.Skip()\n")
+		g.printf("}\n")
+	}
+	g.printf("})\n")
+	g.printf("if err != nil {\n")
+	g.printf("return err\n")
+	g.printf("}\n")
+	g.printf("return d.End()\n")
+	g.printf("}\n\n")
+}
+
+// decode returns the expression decoding the next value of `d` into `ptr`, a pointer to a value of
+// type `t`. `depth` numbers the parameters of nested decoding functions.
+func (g *Generator) decode(ptr string, t *goType, depth int) string {
+	switch t.kind {
+	case kindString:
+		return "jsonlite.String(d, " + ptr + ")"
+	case kindBool:
+		return "jsonlite.Bool(d, " + ptr + ")"
+	case kindInt:
+		return "jsonlite.Int(d, " + ptr + ")"
+	case kindUint:
+		return "jsonlite.Uint(d, " + ptr + ")"
+	case kindFloat:
+		return "jsonlite.Float(d, " + ptr + ")"
+	case kindBytes:
+		return "jsonlite.Bytes(d, " + ptr + ")"
+	case kindMarshaler:
+		return "d.Unmarshaler(" + ptr + ")"
+	}
+
+	// the decoding function of the element type names it, which may need an import
+	for _, path := range t.elem.imports {
+		g.imports[path] = true
+	}
+	helper := map[kind]string{kindPointer: "Pointer", kindSlice: "Slice", kindMap: "Map"}[t.kind]
+	param := "p" + strconv.Itoa(depth)
+	return fmt.Sprintf("jsonlite.%s(d, %s, func(%s *%s) error {\nreturn %s\n})",
+		helper, ptr, param, t.elem.expr, g.decode(param, t.elem, depth+1))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestGenerateJSONTest checks that the methods of package jsontest, which are tested against
+// encoding/json, are those the generator produces.
+func TestGenerateJSONTest(t *testing.T) {
+	dir := filepath.Join("internal", "jsontest")
+	output := filepath.Join(dir, "json.gen.go")
+
+	pkg, err := LoadPackage(dir, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := NewGenerator(pkg).Generate([]string{"Order"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s is out of date, run go generate ./cmd/pdk-jsongen/...", output)
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	src := `package p
+
+type Embedded struct{ Inner }
+type Inner struct{ A int }
+type Array struct{ A [2]int }
+type IntKeys struct{ M map[int]string }
+type Option struct{ A int ` + "`json:\",string\"`" + ` }
+type Iface struct{ A any }
+`
+	if err := os.WriteFile(filepath.Join(dir, "p.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := LoadPackage(dir, filepath.Join(dir, "json.gen.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"Embedded", "Array", "IntKeys", "Option", "Iface", "Missing"} {
+		if _, err := NewGenerator(pkg).Generate([]string{name}); err == nil {
+			t.Errorf("%s: no error for an unsupported type", name)
+		}
+	}
+}
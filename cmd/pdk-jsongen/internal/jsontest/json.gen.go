@@ -0,0 +1,201 @@
+// Code generated by pdk-jsongen. DO NOT EDIT.
+
+package jsontest
+
+import (
+	"github.com/extism/go-pdk/jsonlite"
+)
+
+// MarshalJSON encodes a `Order` as JSON, without reflection.
+func (v Order) MarshalJSON() ([]byte, error) {
+	e := jsonlite.NewEncoder()
+	e.BeginObject()
+	e.Key("id")
+	e.Int(v.ID)
+	e.Key("customer")
+	e.String(v.Customer)
+	if v.Status != "" {
+		e.Key("status")
+		e.String(string(v.Status))
+	}
+	e.Key("paid")
+	e.Bool(v.Paid)
+	e.Key("total")
+	e.Float(v.Total, 64)
+	if v.Discount != 0 {
+		e.Key("discount")
+		e.Float(float64(v.Discount), 32)
+	}
+	e.Key("lines")
+	if v.Lines == nil {
+		e.Null()
+	} else {
+		e.BeginArray()
+		for _, item0 := range v.Lines {
+			e.Marshaler(&item0)
+		}
+		e.EndArray()
+	}
+	if v.Gift != nil {
+		e.Key("gift")
+		e.Marshaler(v.Gift)
+	}
+	e.Key("note")
+	if v.Note == nil {
+		e.Null()
+	} else {
+		e.String(*v.Note)
+	}
+	if len(v.Labels) != 0 {
+		e.Key("labels")
+		e.BeginObject()
+		for _, key0 := range jsonlite.SortedKeys(v.Labels) {
+			item0 := v.Labels[key0]
+			e.Key(string(key0))
+			e.String(item0)
+		}
+		e.EndObject()
+	}
+	e.Key("totals")
+	if v.Totals == nil {
+		e.Null()
+	} else {
+		e.BeginObject()
+		for _, key0 := range jsonlite.SortedKeys(v.Totals) {
+			item0 := v.Totals[key0]
+			e.Key(string(key0))
+			if item0 == nil {
+				e.Null()
+			} else {
+				e.BeginArray()
+				for _, item1 := range item0 {
+					e.Int(int64(item1))
+				}
+				e.EndArray()
+			}
+		}
+		e.EndObject()
+	}
+	if len(v.Signature) != 0 {
+		e.Key("signature")
+		e.ByteSlice([]byte(v.Signature))
+	}
+	e.Key("raw")
+	e.ByteSlice(v.Raw)
+	e.Key("created")
+	e.Marshaler(&v.Created)
+	e.Key("Untagged")
+	e.Uint(uint64(v.Untagged))
+	e.EndObject()
+	return e.Bytes()
+}
+
+// UnmarshalJSON decodes a `Order` from JSON, without reflection.
+func (v *Order) UnmarshalJSON(data []byte) error {
+	d := jsonlite.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	err := d.Object(func(key string) error {
+		switch jsonlite.Field(key, "id", "customer", "status", "paid", "total", "discount", "lines", "gift", "note", "labels", "totals", "signature", "raw", "created", "Untagged") {
+		case "id":
+			return jsonlite.Int(d, &v.ID)
+		case "customer":
+			return jsonlite.String(d, &v.Customer)
+		case "status":
+			return jsonlite.String(d, &v.Status)
+		case "paid":
+			return jsonlite.Bool(d, &v.Paid)
+		case "total":
+			return jsonlite.Float(d, &v.Total)
+		case "discount":
+			return jsonlite.Float(d, &v.Discount)
+		case "lines":
+			return jsonlite.Slice(d, &v.Lines, func(p0 *Line) error {
+				return d.Unmarshaler(p0)
+			})
+		case "gift":
+			return jsonlite.Pointer(d, &v.Gift, func(p0 *Line) error {
+				return d.Unmarshaler(p0)
+			})
+		case "note":
+			return jsonlite.Pointer(d, &v.Note, func(p0 *string) error {
+				return jsonlite.String(d, p0)
+			})
+		case "labels":
+			return jsonlite.Map(d, &v.Labels, func(p0 *string) error {
+				return jsonlite.String(d, p0)
+			})
+		case "totals":
+			return jsonlite.Map(d, &v.Totals, func(p0 *[]int8) error {
+				return jsonlite.Slice(d, p0, func(p1 *int8) error {
+					return jsonlite.Int(d, p1)
+				})
+			})
+		case "signature":
+			return jsonlite.Bytes(d, &v.Signature)
+		case "raw":
+			return jsonlite.Bytes(d, &v.Raw)
+		case "created":
+			return d.Unmarshaler(&v.Created)
+		case "Untagged":
+			return jsonlite.Uint(d, &v.Untagged)
+		default:
+			return d.Skip()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
+
+// MarshalJSON encodes a `Line` as JSON, without reflection.
+func (v Line) MarshalJSON() ([]byte, error) {
+	e := jsonlite.NewEncoder()
+	e.BeginObject()
+	e.Key("sku")
+	e.String(v.SKU)
+	e.Key("quantity")
+	e.Uint(uint64(v.Quantity))
+	e.Key("price")
+	e.Float(v.Price, 64)
+	if len(v.Tags) != 0 {
+		e.Key("tags")
+		e.BeginArray()
+		for _, item0 := range v.Tags {
+			e.String(item0)
+		}
+		e.EndArray()
+	}
+	e.EndObject()
+	return e.Bytes()
+}
+
+// UnmarshalJSON decodes a `Line` from JSON, without reflection.
+func (v *Line) UnmarshalJSON(data []byte) error {
+	d := jsonlite.NewDecoder(data)
+	if d.Null() {
+		return d.End()
+	}
+	err := d.Object(func(key string) error {
+		switch jsonlite.Field(key, "sku", "quantity", "price", "tags") {
+		case "sku":
+			return jsonlite.String(d, &v.SKU)
+		case "quantity":
+			return jsonlite.Uint(d, &v.Quantity)
+		case "price":
+			return jsonlite.Float(d, &v.Price)
+		case "tags":
+			return jsonlite.Slice(d, &v.Tags, func(p0 *string) error {
+				return jsonlite.String(d, p0)
+			})
+		default:
+			return d.Skip()
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return d.End()
+}
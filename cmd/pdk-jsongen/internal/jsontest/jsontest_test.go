@@ -0,0 +1,192 @@
+package jsontest
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// refOrder and refLine mirror Order and Line without their generated methods, so that
+// encoding/json handles them by reflection.
+type refOrder struct {
+	ID        int64             `json:"id"`
+	Customer  string            `json:"customer"`
+	Status    Status            `json:"status,omitempty"`
+	Paid      bool              `json:"paid"`
+	Total     float64           `json:"total"`
+	Discount  float32           `json:"discount,omitempty"`
+	Lines     []refLine         `json:"lines"`
+	Gift      *refLine          `json:"gift,omitempty"`
+	Note      *string           `json:"note"`
+	Labels    Labels            `json:"labels,omitempty"`
+	Totals    map[string][]int8 `json:"totals"`
+	Signature Blob              `json:"signature,omitempty"`
+	Raw       []byte            `json:"raw"`
+	Created   time.Time         `json:"created"`
+	Untagged  uint
+	Secret    string `json:"-"`
+}
+
+type refLine struct {
+	SKU      string   `json:"sku"`
+	Quantity Quantity `json:"quantity"`
+	Price    float64  `json:"price"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+func (o Order) ref() refOrder {
+	r := refOrder{
+		ID: o.ID, Customer: o.Customer, Status: o.Status, Paid: o.Paid, Total: o.Total,
+		Discount: o.Discount, Note: o.Note, Labels: o.Labels, Totals: o.Totals,
+		Signature: o.Signature, Raw: o.Raw, Created: o.Created, Untagged: o.Untagged, Secret: o.Secret,
+	}
+	if o.Lines != nil {
+		r.Lines = []refLine{}
+		for _, line := range o.Lines {
+			r.Lines = append(r.Lines, refLine(line))
+		}
+	}
+	if o.Gift != nil {
+		gift := refLine(*o.Gift)
+		r.Gift = &gift
+	}
+	return r
+}
+
+func orders() []Order {
+	note := "leave at the door <3 & \"thanks\"\n\u2028"
+	return []Order{
+		{},
+		{
+			ID:       -42,
+			Customer: "Zoë \x00 \xff <acme>",
+			Status:   "shipped",
+			Paid:     true,
+			Total:    1234.5,
+			Discount: 0.15,
+			Lines: []Line{
+				{SKU: "a-1", Quantity: 3, Price: 0.1, Tags: []string{"x", ""}},
+				{SKU: "b-2", Quantity: 65535, Price: 1e21},
+			},
+			Gift:      &Line{SKU: "gift", Price: 1e-7},
+			Note:      &note,
+			Labels:    Labels{"b": "2", "a": "1"},
+			Totals:    map[string][]int8{"z": {-128, 127}, "empty": {}, "nil": nil},
+			Signature: Blob("sig"),
+			Raw:       []byte{0, 1, 2, 250},
+			Created:   time.Date(2024, 2, 29, 12, 30, 0, 5, time.FixedZone("", 3600)),
+			Untagged:  7,
+			Secret:    "not encoded",
+		},
+		{Lines: []Line{}, Labels: Labels{}, Totals: map[string][]int8{}, Raw: []byte{}, Discount: 3e-7},
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	for i, order := range orders() {
+		want, err := json.Marshal(order.ref())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := order.MarshalJSON()
+		if err != nil {
+			t.Fatalf("order %d: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("order %d:\ngot  %s\nwant %s", i, got, want)
+		}
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var inputs [][]byte
+	for _, order := range orders() {
+		data, err := json.Marshal(order.ref())
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, data)
+	}
+	inputs = append(inputs,
+		[]byte(`{"ID": 1, "CUSTOMER": "case-insensitive", "unknown": {"nested": [1, null]}, "untagged": 3}`),
+		[]byte(` {"lines": null, "gift": null, "note": null, "labels": null, "raw": null, "secret": "x"} `),
+		[]byte(`{"customer": "\u00e9\ud83d\ude00\/", "total": -0.5e3, "lines": [{"quantity": 1}]}`),
+	)
+
+	for _, data := range inputs {
+		var want refOrder
+		if err := json.Unmarshal(data, &want); err != nil {
+			t.Fatal(err)
+		}
+		var got Order
+		if err := got.UnmarshalJSON(data); err != nil {
+			t.Errorf("%s: %v", data, err)
+			continue
+		}
+		if !reflect.DeepEqual(got.ref(), want) {
+			t.Errorf("%s:\ngot  %+v\nwant %+v", data, got.ref(), want)
+		}
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`[]`,
+		`{"id": "1"}`,
+		`{"id": 1.5}`,
+		`{"quantity": -1}`,
+		`{"lines": [{"quantity": 65536}]}`,
+		`{"paid": 1}`,
+		`{"raw": "not base64!"}`,
+		`{"customer": "unterminated}`,
+		`{"id": 1} trailing`,
+	} {
+		var ref refOrder
+		refErr := json.Unmarshal([]byte(data), &ref)
+		var order Order
+		err := order.UnmarshalJSON([]byte(data))
+		if (err == nil) != (refErr == nil) {
+			t.Errorf("%s: error %v, encoding/json error %v", data, err, refErr)
+		}
+	}
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	order := orders()[1]
+	ref := order.ref()
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			order.MarshalJSON()
+		}
+	})
+	b.Run("reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			json.Marshal(&ref)
+		}
+	})
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data, _ := json.Marshal(orders()[1].ref())
+
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var order Order
+			order.UnmarshalJSON(data)
+		}
+	})
+	b.Run("reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var order refOrder
+			json.Unmarshal(data, &order)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+// Package jsontest holds types covering the features of pdk-jsongen, whose generated methods are
+// checked against encoding/json.
+package jsontest
+
+import "time"
+
+//go:generate go run github.com/extism/go-pdk/cmd/pdk-jsongen -type Order
+
+type Status string
+
+type Quantity uint16
+
+type Labels map[string]string
+
+type Blob []byte
+
+type Order struct {
+	ID        int64             `json:"id"`
+	Customer  string            `json:"customer"`
+	Status    Status            `json:"status,omitempty"`
+	Paid      bool              `json:"paid"`
+	Total     float64           `json:"total"`
+	Discount  float32           `json:"discount,omitempty"`
+	Lines     []Line            `json:"lines"`
+	Gift      *Line             `json:"gift,omitempty"`
+	Note      *string           `json:"note"`
+	Labels    Labels            `json:"labels,omitempty"`
+	Totals    map[string][]int8 `json:"totals"`
+	Signature Blob              `json:"signature,omitempty"`
+	Raw       []byte            `json:"raw"`
+	Created   time.Time         `json:"created"`
+	Untagged  uint
+	Secret    string `json:"-"`
+	internal  int
+}
+
+type Line struct {
+	SKU      string   `json:"sku"`
+	Quantity Quantity `json:"quantity"`
+	Price    float64  `json:"price"`
+	Tags     []string `json:"tags,omitempty"`
+}
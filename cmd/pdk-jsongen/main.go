@@ -0,0 +1,66 @@
+// Command pdk-jsongen generates MarshalJSON and UnmarshalJSON methods for the struct types of a
+// plugin, encoding and decoding them with package github.com/extism/go-pdk/jsonlite instead of
+// the reflection of encoding/json, which is slow and large under TinyGo.
+//
+// The generated methods follow the rules of encoding/json for `json` struct tags (names, "-" and
+// omitempty), pointers, slices, maps with string keys, byte slices (as base64) and named types.
+// Struct types referenced by the selected types get methods too, and types from other packages
+// must implement json.Marshaler and json.Unmarshaler themselves, like time.Time. Embedded fields,
+// arrays, interfaces and the string tag option are not supported.
+//
+// pdk.InputJSON, pdk.OutputJSON and the other JSON helpers of the PDK use these methods directly,
+// so that, built with the nojson tag, a plugin doesn't include encoding/json at all.
+//
+// Usage:
+//
+//	pdk-jsongen [-type CountVowelsInput,CountVowelsOutput] [-o json.gen.go] [dir]
+//
+// or from a `//go:generate pdk-jsongen -type CountVowelsInput` comment. Without -type, methods are
+// generated for every struct type of the package in `dir` (the current directory by default).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	types := flag.String("type", "", "comma-separated list of the struct types to generate methods for (default all)")
+	output := flag.String("o", "json.gen.go", "name of the generated file, in the package directory")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, *output, *types); err != nil {
+		fmt.Fprintln(os.Stderr, "pdk-jsongen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output, types string) error {
+	if !filepath.IsAbs(output) && filepath.Dir(output) == "." {
+		output = filepath.Join(dir, output)
+	}
+
+	pkg, err := LoadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if types != "" {
+		names = strings.Split(types, ",")
+	}
+
+	src, err := NewGenerator(pkg).Generate(names)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
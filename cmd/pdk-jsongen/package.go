@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Package holds the type declarations of the package the methods are generated for.
+type Package struct {
+	Name string
+
+	// types holds the type declarations by name, and files the file each is declared in.
+	types map[string]*ast.TypeSpec
+	files map[string]*ast.File
+	// methods holds, for each type name, the names of its declared methods.
+	methods map[string]map[string]bool
+}
+
+// LoadPackage parses the Go files of the package in `dir`, except test files and `output`.
+func LoadPackage(dir, output string) (*Package, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	pkg := &Package{
+		types:   map[string]*ast.TypeSpec{},
+		files:   map[string]*ast.File{},
+		methods: map[string]map[string]bool{},
+	}
+	fset := token.NewFileSet()
+	for _, p := range paths {
+		if strings.HasSuffix(p, "_test.go") || filepath.Clean(p) == filepath.Clean(output) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.Name == "" {
+			pkg.Name = file.Name.Name
+		}
+		pkg.add(file)
+	}
+	if pkg.Name == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return pkg, nil
+}
+
+func (p *Package) add(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				spec := spec.(*ast.TypeSpec)
+				// the same type may be declared by files with different build tags
+				if _, ok := p.types[spec.Name.Name]; !ok {
+					p.types[spec.Name.Name] = spec
+					p.files[spec.Name.Name] = file
+				}
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) != 1 {
+				continue
+			}
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				if p.methods[ident.Name] == nil {
+					p.methods[ident.Name] = map[string]bool{}
+				}
+				p.methods[ident.Name][decl.Name.Name] = true
+			}
+		}
+	}
+}
+
+// structTypes returns the names of the struct types of the package, in order.
+func (p *Package) structTypes() []string {
+	var names []string
+	for name, spec := range p.types {
+		if _, ok := spec.Type.(*ast.StructType); ok && spec.TypeParams == nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// importPath returns the path of the package imported as `name` by the file declaring `typeName`.
+func (p *Package) importPath(typeName, name string) (string, error) {
+	for _, imp := range p.files[typeName].Imports {
+		importPath, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return importPath, nil
+			}
+			continue
+		}
+		if defaultName(importPath) == name {
+			return importPath, nil
+		}
+	}
+	return "", fmt.Errorf("cannot find the import of package %s used by %s", name, typeName)
+}
+
+// defaultName guesses the name of the package at `importPath`, e.g. "yaml" for
+// "gopkg.in/yaml.v3" and "pdk" for "github.com/extism/go-pdk".
+func defaultName(importPath string) string {
+	name := path.Base(importPath)
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	if _, err := strconv.Atoi(strings.TrimPrefix(name, "v")); err == nil && strings.HasPrefix(name, "v") {
+		name = path.Base(path.Dir(importPath))
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
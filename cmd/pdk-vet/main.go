@@ -0,0 +1,24 @@
+// Command pdk-vet reports common mistakes in Extism plugins written with package
+// github.com/extism/go-pdk (see package pdkvet for the list of checks).
+//
+// It runs standalone, or as a tool of go vet:
+//
+//	GOOS=wasip1 GOARCH=wasm pdk-vet [-tinygo] ./...
+//	GOOS=wasip1 GOARCH=wasm go vet -vettool=$(which pdk-vet) -tags std ./...
+//
+// The standard Go compiler ignores //export directives, so pdk-vet reports them unless the file is
+// excluded from standard builds by its build constraint, or -tinygo is passed.
+//
+// This command is a module of its own, as it needs a recent golang.org/x/tools, and is left out of
+// the repository workspace: build it with GOWORK=off, or `make vet`.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/singlechecker"
+
+	"github.com/extism/go-pdk/cmd/pdk-vet/pdkvet"
+)
+
+func main() {
+	singlechecker.Main(pdkvet.Analyzer)
+}
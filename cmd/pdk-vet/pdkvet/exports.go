@@ -0,0 +1,349 @@
+package pdkvet
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+var tinygo bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&tinygo, "tinygo", false, "the package is built with TinyGo, which supports //export directives")
+}
+
+// An export is a function the host can call: a function declared with an export directive, or
+// one registered with pdk.Register.
+type export struct {
+	// name is the export name, or "" if the name passed to pdk.Register isn't constant.
+	name string
+	pos  token.Pos
+	// directive is "//export" or "//go:wasmexport", or "" for a registered function.
+	directive string
+
+	// decl is the declaration of the function, or nil if a function literal is registered.
+	decl *ast.FuncDecl
+	typ  *ast.FuncType
+	body *ast.BlockStmt
+}
+
+func (e *export) String() string {
+	if e.name != "" {
+		return e.name
+	}
+	if e.decl != nil {
+		return e.decl.Name.Name
+	}
+	return "registered with pdk.Register"
+}
+
+// parseDirective returns the kind of the export directive in comment `text` and the name it
+// exports, or "" if `text` isn't one.
+func parseDirective(text string) (kind, name string) {
+	for _, kind := range []string{"//export", "//go:wasmexport"} {
+		rest, ok := strings.CutPrefix(text, kind)
+		if !ok || rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+			continue
+		}
+		if fields := strings.Fields(rest); len(fields) > 0 {
+			name = fields[0]
+		}
+		return kind, name
+	}
+	return "", ""
+}
+
+// funcDecls returns the declarations of the functions of the package, by object.
+func funcDecls(pass *analysis.Pass) map[*types.Func]*ast.FuncDecl {
+	decls := map[*types.Func]*ast.FuncDecl{}
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok {
+				if fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func); ok {
+					decls[fn] = decl
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// findExports returns the exports of the package, in order: functions with export directives
+// first, then registered functions.
+func findExports(pass *analysis.Pass) []*export {
+	var exports []*export
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.FuncDecl)
+			if !ok || decl.Doc == nil {
+				continue
+			}
+			for _, c := range decl.Doc.List {
+				if kind, name := parseDirective(c.Text); kind != "" {
+					exports = append(exports, &export{
+						name:      name,
+						pos:       c.Pos(),
+						directive: kind,
+						decl:      decl,
+						typ:       decl.Type,
+						body:      decl.Body,
+					})
+				}
+			}
+		}
+	}
+
+	decls := funcDecls(pass)
+	for _, file := range pass.Files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || callee(pass.TypesInfo, call) != "Register" || len(call.Args) != 2 {
+				return true
+			}
+			e := &export{pos: call.Pos()}
+			if tv := pass.TypesInfo.Types[call.Args[0]]; tv.Value != nil && tv.Value.Kind() == constant.String {
+				e.name = constant.StringVal(tv.Value)
+			}
+			switch fn := ast.Unparen(call.Args[1]).(type) {
+			case *ast.FuncLit:
+				e.typ, e.body = fn.Type, fn.Body
+			case *ast.Ident:
+				if e.decl = decls[funcObj(pass.TypesInfo, fn)]; e.decl != nil {
+					e.typ, e.body = e.decl.Type, e.decl.Body
+				}
+			case *ast.SelectorExpr:
+				if e.decl = decls[funcObj(pass.TypesInfo, fn.Sel)]; e.decl != nil {
+					e.typ, e.body = e.decl.Type, e.decl.Body
+				}
+			}
+			exports = append(exports, e)
+			return true
+		})
+	}
+	return exports
+}
+
+func funcObj(info *types.Info, ident *ast.Ident) *types.Func {
+	fn, _ := info.Uses[ident].(*types.Func)
+	return fn
+}
+
+// checkDirectives reports the export directives the compiler ignores or rejects, and the export
+// names used by two functions.
+func checkDirectives(pass *analysis.Pass, exports []*export) {
+	registered := map[*ast.FuncDecl]bool{}
+	for _, e := range exports {
+		if e.directive == "" && e.decl != nil {
+			registered[e.decl] = true
+		}
+	}
+
+	for _, file := range pass.Files {
+		docs := map[*ast.CommentGroup]bool{}
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Doc != nil {
+				docs[decl.Doc] = true
+			}
+		}
+		for _, group := range file.Comments {
+			for _, c := range group.List {
+				if kind, _ := parseDirective(c.Text); kind != "" && !docs[group] {
+					pass.ReportRangef(c, "%s directive is not attached to a function: it must directly precede the func declaration", kind)
+				} else if misspelled(c.Text) && docs[group] {
+					pass.ReportRangef(c, "%q is not a directive: remove the space after //", c.Text)
+				}
+			}
+		}
+	}
+
+	byDecl := map[*ast.FuncDecl]string{}
+	byName := map[string]*export{}
+	for _, e := range exports {
+		if e.directive != "" {
+			switch {
+			case e.name == "":
+				pass.Reportf(e.pos, "%s directive without an export name", e.directive)
+			case e.decl.Recv != nil:
+				pass.Reportf(e.pos, "method %s can't be exported", e.decl.Name.Name)
+			case e.decl.Type.TypeParams != nil:
+				pass.Reportf(e.pos, "generic function %s can't be exported", e.decl.Name.Name)
+			case e.directive == "//export" && !tinygo && !registered[e.decl] && builtByGc(pass, e.pos):
+				pass.Reportf(e.pos, "the Go compiler ignores //export: export %s with //go:wasmexport or pdk.Register (or pass -tinygo if it is built with TinyGo)", e.name)
+			}
+			if kind, ok := byDecl[e.decl]; ok && kind != e.directive {
+				pass.Reportf(e.pos, "%s has both //export and //go:wasmexport directives", e.decl.Name.Name)
+			}
+			byDecl[e.decl] = e.directive
+		}
+
+		if e.name == "" {
+			continue
+		}
+		if other, ok := byName[e.name]; ok && (e.decl == nil || other.decl != e.decl) {
+			posn := pass.Fset.Position(other.pos)
+			pass.Reportf(e.pos, "export name %s is already used at %s:%d", e.name, filepath.Base(posn.Filename), posn.Line)
+			continue
+		}
+		byName[e.name] = e
+	}
+}
+
+// misspelled reports whether comment `text` is an export directive with a space after the //,
+// which makes it a plain comment.
+func misspelled(text string) bool {
+	if strings.HasPrefix(text, "// go:wasmexport") {
+		return true
+	}
+	rest, ok := strings.CutPrefix(text, "// export ")
+	return ok && len(strings.Fields(rest)) == 1
+}
+
+// builtByGc reports whether the file at `pos` is built by the standard Go compiler, going by its
+// build constraint: a file for TinyGo requires the tinygo tag, or excludes the std tag used by
+// the PDK examples.
+func builtByGc(pass *analysis.Pass, pos token.Pos) bool {
+	for _, file := range pass.Files {
+		if file.FileStart > pos || pos >= file.FileEnd {
+			continue
+		}
+		for _, group := range file.Comments {
+			if group.Pos() > file.Package {
+				break
+			}
+			for _, c := range group.List {
+				if !constraint.IsGoBuild(c.Text) {
+					continue
+				}
+				expr, err := constraint.Parse(c.Text)
+				if err != nil {
+					return true
+				}
+				return expr.Eval(func(tag string) bool { return tag != "tinygo" })
+			}
+		}
+	}
+	return true
+}
+
+// checkErrors reports the exports returning a non-zero constant without calling pdk.SetError or
+// pdk.SetErrorString first.
+func checkErrors(pass *analysis.Pass, exports []*export) {
+	c := &errorChecker{
+		pass:  pass,
+		decls: funcDecls(pass),
+		memo:  map[*types.Func]bool{},
+	}
+	checked := map[*ast.BlockStmt]bool{}
+	for _, e := range exports {
+		if e.body == nil || checked[e.body] || !returnsInt(pass.TypesInfo, e.typ) {
+			continue
+		}
+		checked[e.body] = true
+		c.checkReturns(e, e.body.List, false)
+	}
+}
+
+func returnsInt(info *types.Info, typ *ast.FuncType) bool {
+	if typ.Results == nil || typ.Results.NumFields() != 1 {
+		return false
+	}
+	basic, ok := info.TypeOf(typ.Results.List[0].Type).Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
+
+type errorChecker struct {
+	pass  *analysis.Pass
+	decls map[*types.Func]*ast.FuncDecl
+	// memo holds whether each function of the package already looked at sets the error.
+	memo map[*types.Func]bool
+}
+
+// checkReturns reports the returns of non-zero constants among `stmts` that no call to SetError
+// precedes, `set` telling whether one precedes `stmts`. A call made on some paths only is assumed
+// to cover the returns that follow it.
+func (c *errorChecker) checkReturns(e *export, stmts []ast.Stmt, set bool) {
+	for _, stmt := range stmts {
+		switch s := stmt.(type) {
+		case *ast.ReturnStmt:
+			if !set && len(s.Results) == 1 {
+				tv := c.pass.TypesInfo.Types[s.Results[0]]
+				if tv.Value != nil && tv.Value.Kind() == constant.Int && constant.Sign(tv.Value) != 0 {
+					c.pass.ReportRangef(s, "export %s returns %s without calling pdk.SetError first, so the host gets no error message", e, tv.Value)
+				}
+			}
+		case *ast.BlockStmt:
+			c.checkReturns(e, s.List, set)
+		case *ast.LabeledStmt:
+			c.checkReturns(e, []ast.Stmt{s.Stmt}, set)
+		case *ast.IfStmt:
+			set := set || s.Init != nil && c.setsError(s.Init) || c.setsError(s.Cond)
+			c.checkReturns(e, s.Body.List, set)
+			if s.Else != nil {
+				c.checkReturns(e, []ast.Stmt{s.Else}, set)
+			}
+		case *ast.ForStmt:
+			c.checkReturns(e, s.Body.List, set)
+		case *ast.RangeStmt:
+			c.checkReturns(e, s.Body.List, set)
+		case *ast.SwitchStmt:
+			c.checkClauses(e, s.Body, set)
+		case *ast.TypeSwitchStmt:
+			c.checkClauses(e, s.Body, set)
+		case *ast.SelectStmt:
+			c.checkClauses(e, s.Body, set)
+		}
+		set = set || c.setsError(stmt)
+	}
+}
+
+func (c *errorChecker) checkClauses(e *export, body *ast.BlockStmt, set bool) {
+	for _, clause := range body.List {
+		switch clause := clause.(type) {
+		case *ast.CaseClause:
+			c.checkReturns(e, clause.Body, set)
+		case *ast.CommClause:
+			c.checkReturns(e, clause.Body, set)
+		}
+	}
+}
+
+// setsError reports whether `node` calls pdk.SetError or pdk.SetErrorString, directly or through
+// functions of the package.
+func (c *errorChecker) setsError(node ast.Node) bool {
+	found := false
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.CallExpr:
+			switch callee(c.pass.TypesInfo, n) {
+			case "SetError", "SetErrorString":
+				found = true
+			default:
+				if fn := typeutil.StaticCallee(c.pass.TypesInfo, n); fn != nil && c.decls[fn] != nil {
+					found = c.funcSetsError(fn)
+				}
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+func (c *errorChecker) funcSetsError(fn *types.Func) bool {
+	if set, ok := c.memo[fn]; ok {
+		return set
+	}
+	// guard against recursion
+	c.memo[fn] = false
+	set := c.decls[fn].Body != nil && c.setsError(c.decls[fn].Body)
+	c.memo[fn] = set
+	return set
+}
@@ -0,0 +1,210 @@
+package pdkvet
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// A block is a local variable holding a host memory block.
+type block struct {
+	v *types.Var
+
+	// alloc is the function the block is allocated with, such as "AllocateString" or
+	// "Allocator.AllocateString", or "" if it comes from elsewhere.
+	alloc    string
+	allocPos token.Pos
+
+	// handled is set when the block is freed or passed along, which makes someone else
+	// responsible for freeing it.
+	handled bool
+	// kept is set when the block is detached from the Allocator or Arena it was allocated with.
+	kept bool
+
+	outputs []*ast.CallExpr
+	frees   []*ast.CallExpr
+	// deferred holds the frees which are deferred.
+	deferred map[*ast.CallExpr]bool
+}
+
+var allocators = map[string]bool{
+	"Allocate":       true,
+	"AllocateBytes":  true,
+	"AllocateString": true,
+	"AllocateJSON":   true,
+}
+
+// scoped returns the receiver of the method `alloc` names, such as "Allocator", or "" if `alloc`
+// names a function.
+func scoped(alloc string) string {
+	recv, _, _ := strings.Cut(alloc, ".")
+	if recv == alloc {
+		return ""
+	}
+	return recv
+}
+
+// checkMemory reports the blocks allocated but never freed, and the blocks freed after being
+// passed to pdk.OutputMemory.
+func checkMemory(pass *analysis.Pass) {
+	var blocks []*block
+	byVar := map[*types.Var]*block{}
+	get := func(v *types.Var) *block {
+		b := byVar[v]
+		if b == nil {
+			b = &block{v: v, deferred: map[*ast.CallExpr]bool{}}
+			byVar[v] = b
+			blocks = append(blocks, b)
+		}
+		return b
+	}
+
+	allocation := func(expr ast.Expr) string {
+		call, ok := ast.Unparen(expr).(*ast.CallExpr)
+		if !ok {
+			return ""
+		}
+		name := callee(pass.TypesInfo, call)
+		if recv := scoped(name); recv == "Allocator" || recv == "Arena" {
+			if allocators[name[len(recv)+1:]] {
+				return name
+			}
+		}
+		if allocators[name] {
+			return name
+		}
+		return ""
+	}
+	assign := func(lhs ast.Expr, rhs ast.Expr) {
+		alloc := allocation(rhs)
+		if alloc == "" {
+			return
+		}
+		if ident, ok := lhs.(*ast.Ident); ok && ident.Name == "_" && scoped(alloc) == "" {
+			pass.ReportRangef(rhs, "the block allocated by pdk.%s is discarded, so it is never freed", alloc)
+			return
+		}
+		if v := localVar(pass.TypesInfo, lhs); v != nil {
+			if b := get(v); b.alloc == "" {
+				b.alloc, b.allocPos = alloc, rhs.Pos()
+			}
+		}
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	filter := []ast.Node{
+		(*ast.AssignStmt)(nil),
+		(*ast.ValueSpec)(nil),
+		(*ast.ExprStmt)(nil),
+		(*ast.Ident)(nil),
+	}
+	inspect.WithStack(filter, func(n ast.Node, push bool, stack []ast.Node) bool {
+		if !push {
+			return true
+		}
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			// the allocations return a single value, or a value and an error
+			if len(n.Rhs) == 1 {
+				assign(n.Lhs[0], n.Rhs[0])
+			} else if len(n.Lhs) == len(n.Rhs) {
+				for i := range n.Rhs {
+					assign(n.Lhs[i], n.Rhs[i])
+				}
+			}
+		case *ast.ValueSpec:
+			if len(n.Values) == 1 {
+				assign(n.Names[0], n.Values[0])
+			} else if len(n.Names) == len(n.Values) {
+				for i := range n.Values {
+					assign(n.Names[i], n.Values[i])
+				}
+			}
+		case *ast.ExprStmt:
+			if alloc := allocation(n.X); alloc != "" && scoped(alloc) == "" {
+				pass.ReportRangef(n, "the block allocated by pdk.%s is discarded, so it is never freed", alloc)
+			}
+		case *ast.Ident:
+			if v, ok := pass.TypesInfo.Uses[n].(*types.Var); ok && isMemory(v.Type()) && localVar(pass.TypesInfo, n) != nil {
+				use(pass, get(v), n, stack)
+			}
+		}
+		return true
+	})
+
+	for _, b := range blocks {
+		switch recv := scoped(b.alloc); {
+		case b.alloc != "" && recv == "" && !b.handled && len(b.frees) == 0 && len(b.outputs) == 0:
+			pass.Reportf(b.allocPos, "%s is allocated by pdk.%s but never freed", b.v.Name(), b.alloc)
+		case recv != "" && !b.kept:
+			for _, output := range b.outputs {
+				pass.ReportRangef(output, "%s is freed by its %s before the host reads the output: pass it to %s.Keep first", b.v.Name(), recv, recv)
+			}
+		}
+		for _, free := range b.frees {
+			for _, output := range b.outputs {
+				if b.deferred[free] || free.Pos() > output.Pos() {
+					pass.ReportRangef(free, "%s is freed after being passed to pdk.OutputMemory, but the host reads the output once the export returns", b.v.Name())
+					break
+				}
+			}
+		}
+	}
+}
+
+// use records the use of block `b` by `ident`, the last node of `stack`.
+func use(pass *analysis.Pass, b *block, ident *ast.Ident, stack []ast.Node) {
+	parent := func(i int) ast.Node {
+		if i < len(stack) {
+			return stack[len(stack)-1-i]
+		}
+		return nil
+	}
+
+	switch p := parent(1).(type) {
+	case *ast.AssignStmt:
+		for _, lhs := range p.Lhs {
+			if lhs == ident {
+				// assigned, not used
+				return
+			}
+		}
+	case *ast.SelectorExpr:
+		call, ok := parent(2).(*ast.CallExpr)
+		if !ok || call.Fun != p {
+			break
+		}
+		switch p.Sel.Name {
+		case "Free":
+			b.frees = append(b.frees, call)
+			if d, ok := parent(3).(*ast.DeferStmt); ok && d.Call == call {
+				b.deferred[call] = true
+			}
+		case "Offset":
+			// returning the offset hands the block over to the caller
+			if _, ok := parent(3).(*ast.ReturnStmt); ok {
+				b.handled = true
+			}
+		}
+		return
+	case *ast.CallExpr:
+		switch callee(pass.TypesInfo, p) {
+		case "OutputMemory":
+			b.outputs = append(b.outputs, p)
+			return
+		case "Allocator.Keep", "Arena.Keep":
+			b.kept = true
+		}
+	}
+	b.handled = true
+}
+
+func isMemory(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	return ok && named.Obj().Name() == "Memory" && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == memoryPath
+}
@@ -0,0 +1,102 @@
+// Package pdkvet defines an analyzer reporting common mistakes in Extism plugins written with
+// package github.com/extism/go-pdk:
+//
+//   - exports returning a non-zero code without calling pdk.SetError or pdk.SetErrorString first,
+//     which leaves the host with an empty error message;
+//   - blocks allocated with pdk.Allocate, pdk.AllocateBytes, pdk.AllocateString or
+//     pdk.AllocateJSON that are never freed nor handed over;
+//   - blocks passed to pdk.OutputMemory and then freed, by the plugin or by the Scope or Arena they
+//     were allocated from, before the host reads the output;
+//   - export directives the compiler ignores or rejects: //export under the standard Go compiler,
+//     directives detached from their function or misspelled with a space, and export names used
+//     twice.
+//
+// The checks are local to each function and only follow blocks held by local variables, so they
+// can miss mistakes made through helper functions, struct fields or slices.
+package pdkvet
+
+import (
+	"go/ast"
+	"go/types"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const (
+	pdkPath    = "github.com/extism/go-pdk"
+	memoryPath = "github.com/extism/go-pdk/internal/memory"
+)
+
+// Analyzer reports common mistakes in Extism plugins.
+var Analyzer = &analysis.Analyzer{
+	Name:     "pdkvet",
+	Doc:      "report common mistakes in Extism plugins using github.com/extism/go-pdk",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if !importsPDK(pass.Pkg) {
+		return nil, nil
+	}
+
+	exports := findExports(pass)
+	checkDirectives(pass, exports)
+	checkErrors(pass, exports)
+	checkMemory(pass)
+	return nil, nil
+}
+
+func importsPDK(pkg *types.Package) bool {
+	for _, imp := range pkg.Imports() {
+		if imp.Path() == pdkPath {
+			return true
+		}
+	}
+	return false
+}
+
+// callee returns the name of the pdk function or method called by `call`, such as "OutputMemory"
+// or "Memory.Free", or "" if it calls something else.
+func callee(info *types.Info, call *ast.CallExpr) string {
+	fn, ok := typeutil.Callee(info, call).(*types.Func)
+	if !ok || fn.Pkg() == nil {
+		return ""
+	}
+	// Memory and Arena are aliases of types of the internal memory package
+	if path := fn.Pkg().Path(); path != pdkPath && path != memoryPath {
+		return ""
+	}
+
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		if fn.Pkg().Path() != pdkPath {
+			return ""
+		}
+		return fn.Name()
+	}
+	t := recv.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := t.(*types.Named)
+	if !ok {
+		return ""
+	}
+	return named.Obj().Name() + "." + fn.Name()
+}
+
+// localVar returns the local variable `expr` names, if it does.
+func localVar(info *types.Info, expr ast.Expr) *types.Var {
+	ident, ok := ast.Unparen(expr).(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	v, ok := info.ObjectOf(ident).(*types.Var)
+	if !ok || v.Pkg() == nil || v.Parent() == v.Pkg().Scope() || v.IsField() {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,19 @@
+package pdkvet
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "plugins/errors", "plugins/memory", "plugins/directives", "plugins/tinygo")
+}
+
+func TestAnalyzerTinyGo(t *testing.T) {
+	if err := Analyzer.Flags.Set("tinygo", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer Analyzer.Flags.Set("tinygo", "false")
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "plugins/tinygoflag")
+}
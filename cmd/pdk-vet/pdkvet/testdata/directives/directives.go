@@ -0,0 +1,64 @@
+package directives
+
+import (
+	"github.com/extism/go-pdk"
+)
+
+//export ignored // want `the Go compiler ignores //export: export ignored with //go:wasmexport or pdk.Register`
+func ignored() int32 {
+	return 0
+}
+
+//export registered
+func registered() int32 {
+	return 0
+}
+
+func init() {
+	pdk.Register("registered", registered)
+	pdk.Register("duplicate", func() int32 { return 0 }) // want `export name duplicate is already used at directives.go:22`
+}
+
+//go:wasmexport duplicate
+func duplicate() int32 {
+	return 0
+}
+
+/* want `//go:wasmexport directive without an export name` */ //go:wasmexport
+func unnamed() int32 {
+	return 0
+}
+
+type plugin struct{}
+
+//go:wasmexport method // want `method method can't be exported`
+func (plugin) method() int32 {
+	return 0
+}
+
+//go:wasmexport generic // want `generic function generic can't be exported`
+func generic[T any]() int32 {
+	return 0
+}
+
+//go:wasmexport both
+//export both // want `both has both //export and //go:wasmexport directives` `the Go compiler ignores //export`
+func both() int32 {
+	return 0
+}
+
+//go:wasmexport detached // want `//go:wasmexport directive is not attached to a function`
+
+func detached() int32 {
+	return 0
+}
+
+// go:wasmexport misspelled // want `"// go:wasmexport misspelled.*" is not a directive: remove the space after //`
+func misspelled() int32 {
+	return 0
+}
+
+// export is not a directive in this sentence, so it is left alone.
+func prose() int32 {
+	return 0
+}
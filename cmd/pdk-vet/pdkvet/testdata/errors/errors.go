@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"errors"
+
+	"github.com/extism/go-pdk"
+)
+
+//go:wasmexport missing
+func missing() int32 {
+	if pdk.InputString() == "" {
+		return 1 // want `export missing returns 1 without calling pdk.SetError first`
+	}
+	return 0
+}
+
+//go:wasmexport set
+func set() int32 {
+	if pdk.InputString() == "" {
+		pdk.SetErrorString("empty input")
+		return 1
+	}
+	if err := pdk.InputJSON(&struct{}{}); err != nil {
+		pdk.SetError(err)
+		return 2
+	}
+	return 0
+}
+
+// fail sets the error for the exports calling it.
+func fail(err error) int32 {
+	pdk.SetError(err)
+	return 1
+}
+
+//go:wasmexport helper
+func helper() int32 {
+	code := fail(errors.New("always"))
+	return code
+}
+
+//go:wasmexport helper_condition
+func helperCondition() int32 {
+	if fail(errors.New("always")) != 0 {
+		return 1
+	}
+	return 0
+}
+
+//go:wasmexport switched
+func switched() int32 {
+	switch pdk.InputString() {
+	case "a":
+		return 0
+	case "b":
+		return 3 // want `export switched returns 3 without calling pdk.SetError first`
+	}
+	return 0
+}
+
+func registered() int32 {
+	return 1 // want `export registered returns 1 without calling pdk.SetError first`
+}
+
+func init() {
+	pdk.Register("registered", registered)
+	pdk.Register("literal", func() int32 {
+		for range pdk.InputString() {
+			return -1 // want `export literal returns -1 without calling pdk.SetError first`
+		}
+		return 0
+	})
+}
+
+// notExported isn't an export, so it may return codes freely.
+func notExported() int32 {
+	return 1
+}
@@ -0,0 +1,7 @@
+module plugins
+
+go 1.21.0
+
+require github.com/extism/go-pdk v0.0.0
+
+replace github.com/extism/go-pdk => ../../../..
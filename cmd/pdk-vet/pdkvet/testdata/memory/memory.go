@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"github.com/extism/go-pdk"
+)
+
+func leaked() {
+	mem := pdk.AllocateString("leaked") // want `mem is allocated by pdk.AllocateString but never freed`
+	pdk.Log(pdk.LogInfo, string(mem.ReadBytes()))
+}
+
+func discarded() {
+	pdk.AllocateBytes(nil) // want `the block allocated by pdk.AllocateBytes is discarded`
+	_ = pdk.Allocate(8)    // want `the block allocated by pdk.Allocate is discarded`
+}
+
+func freed() {
+	mem := pdk.AllocateString("freed")
+	mem.Free()
+
+	deferred := pdk.AllocateString("deferred")
+	defer deferred.Free()
+
+	json, err := pdk.AllocateJSON(map[string]int{})
+	if err != nil {
+		return
+	}
+	defer json.Free()
+}
+
+// handedOver returns the offset of its block, which the caller frees.
+func handedOver() uint64 {
+	mem := pdk.AllocateString("handed over")
+	return mem.Offset()
+}
+
+// passed gives its block to a function, which is then responsible for it.
+func passed() {
+	mem := pdk.AllocateString("passed")
+	consume(mem)
+}
+
+func consume(mem pdk.Memory) {
+	mem.Free()
+}
+
+func output() {
+	mem := pdk.AllocateString("output")
+	pdk.OutputMemory(mem)
+}
+
+func outputFreed() {
+	mem := pdk.AllocateString("output")
+	pdk.OutputMemory(mem)
+	mem.Free() // want `mem is freed after being passed to pdk.OutputMemory`
+}
+
+func outputDeferred() {
+	mem := pdk.AllocateString("output")
+	defer mem.Free() // want `mem is freed after being passed to pdk.OutputMemory`
+	pdk.OutputMemory(mem)
+}
+
+func scoped() error {
+	return pdk.Scope(func(s *pdk.Allocator) error {
+		// blocks of the scope are freed when it returns
+		s.AllocateString("temporary")
+		lost := s.AllocateString("lost")
+		pdk.OutputMemory(lost) // want `lost is freed by its Allocator before the host reads the output: pass it to Allocator.Keep first`
+
+		kept := s.Keep(s.AllocateString("kept"))
+		pdk.OutputMemory(kept)
+
+		alsoKept := s.AllocateString("also kept")
+		s.Keep(alsoKept)
+		pdk.OutputMemory(alsoKept)
+		return nil
+	})
+}
+
+func arena() {
+	a := pdk.NewArena()
+	defer a.Release()
+
+	lost := a.AllocateBytes([]byte("lost"))
+	pdk.OutputMemory(lost) // want `lost is freed by its Arena before the host reads the output: pass it to Arena.Keep first`
+
+	kept := a.AllocateBytes([]byte("kept"))
+	a.Keep(kept)
+	pdk.OutputMemory(kept)
+}
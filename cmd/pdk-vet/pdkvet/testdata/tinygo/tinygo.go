@@ -0,0 +1,15 @@
+//go:build !std
+
+// Package tinygo is built with TinyGo only, as the PDK examples exclude the std tag for TinyGo
+// builds, so its //export directives are not flagged.
+package tinygo
+
+import (
+	"github.com/extism/go-pdk"
+)
+
+//export greet
+func greet() int32 {
+	pdk.OutputString("Hello")
+	return 0
+}
@@ -0,0 +1,12 @@
+// Package tinygoflag is checked with -tinygo, which allows //export directives in any file.
+package tinygoflag
+
+import (
+	"github.com/extism/go-pdk"
+)
+
+//export greet
+func greet() int32 {
+	pdk.OutputString("Hello")
+	return 0
+}
@@ -0,0 +1,534 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+)
+
+const (
+	pdkImport    = "github.com/extism/go-pdk"
+	schemaImport = "github.com/extism/go-pdk/schema"
+
+	// schemaVar is the name of the variable holding the schema, when validation is enabled.
+	schemaVar = "xtpSchema"
+
+	// describeExport is the name of the export sending the plugin description, as registered by
+	// pdk.Describe.
+	describeExport = "describe"
+)
+
+// Generator produces Go source files from a `Schema`.
+type Generator struct {
+	// Validate makes the export wrappers validate their input and output against the schema,
+	// which is embedded in the generated code.
+	Validate bool
+	// Describe makes the bindings describe the exports and host functions of the schema to the
+	// PDK, and export the plugin description (see pdk.Describe).
+	Describe bool
+
+	schema  *Schema
+	pkg     string
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+// NewGenerator returns a `Generator` for `schema`, emitting code in package `pkg`.
+func NewGenerator(schema *Schema, pkg string) *Generator {
+	return &Generator{schema: schema, pkg: pkg}
+}
+
+// Bindings returns the source of the types, export wrappers and import wrappers.
+func (g *Generator) Bindings() ([]byte, error) {
+	g.reset()
+
+	for _, name := range sortedKeys(g.schema.Components.Schemas) {
+		g.component(name, g.schema.Components.Schemas[name])
+	}
+	for _, name := range sortedKeys(g.schema.Exports) {
+		g.export(name, g.schema.Exports[name])
+	}
+	for _, name := range sortedKeys(g.schema.Imports) {
+		g.hostImport(name, g.schema.Imports[name])
+	}
+	if g.Validate {
+		g.schemaLiteral()
+	}
+	if g.Describe {
+		if _, ok := g.schema.Exports[describeExport]; ok {
+			return nil, fmt.Errorf("cannot describe the plugin: the schema already has a %q export", describeExport)
+		}
+		g.description()
+	}
+
+	return g.source("// Code generated by xtp-gen-go. DO NOT EDIT.\n\n")
+}
+
+// Stubs returns the source of unimplemented versions of the functions called by the export
+// wrappers, for the plugin author to fill out. In package main, they come with the empty main
+// function the compilers require.
+func (g *Generator) Stubs() ([]byte, error) {
+	g.reset()
+
+	if g.pkg == "main" {
+		g.printf("// main is required to build the plugin, but the host calls its exports directly.\n")
+		g.printf("func main() {}\n\n")
+	}
+
+	for _, name := range sortedKeys(g.schema.Exports) {
+		fn := g.schema.Exports[name]
+		g.funcDoc(goName(name), fn.Description, fmt.Sprintf("implements the %q export.", name))
+		g.printf("func %s(%s) %s {\n", goName(name), g.params(fn), g.results(fn))
+		g.printf("\t// TODO: fill out your implementation here\n")
+		g.printf("\tpanic(\"Function not implemented.\")\n")
+		g.printf("}\n\n")
+	}
+
+	return g.source("")
+}
+
+func (g *Generator) reset() {
+	g.buf.Reset()
+	g.imports = map[string]bool{}
+}
+
+func (g *Generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *Generator) source(header string) ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteString(header)
+	fmt.Fprintf(&out, "package %s\n\n", g.pkg)
+
+	if len(g.imports) > 0 {
+		var std, other []string
+		for _, path := range sortedKeys(g.imports) {
+			if strings.Contains(path, ".") {
+				other = append(other, path)
+			} else {
+				std = append(std, path)
+			}
+		}
+
+		out.WriteString("import (\n")
+		for _, path := range std {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+		if len(std) > 0 && len(other) > 0 {
+			out.WriteString("\n")
+		}
+		for _, path := range other {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+		out.WriteString(")\n\n")
+	}
+
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// doc writes `text` as a comment, with each line prefixed by `indent`.
+func (g *Generator) doc(text, indent string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		g.printf("%s// %s\n", indent, strings.TrimSpace(line))
+	}
+}
+
+// funcDoc writes the doc comment of the function `name`: its schema `description`, starting with
+// the name as Go doc comments do, or `fallback` if it has none.
+func (g *Generator) funcDoc(name, description, fallback string) {
+	text := strings.TrimSpace(description)
+	if text == "" {
+		text = fallback
+	}
+	if !strings.HasPrefix(text, name+" ") {
+		text = name + " " + lowerFirst(text)
+	}
+	g.doc(text, "")
+}
+
+// lowerFirst returns `text` with its first letter in lower case, unless its first word is an
+// initialism such as "HTTP".
+func lowerFirst(text string) string {
+	runes := []rune(text)
+	if len(runes) > 1 && unicode.IsUpper(runes[1]) {
+		return text
+	}
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+func (g *Generator) component(name string, t *Type) {
+	typeName := goName(name)
+	g.doc(t.Description, "")
+
+	switch {
+	case len(t.Enum) > 0:
+		g.printf("type %s string\n\n", typeName)
+		g.printf("const (\n")
+		for _, value := range t.Enum {
+			g.printf("\t%s%s %s = %q\n", typeName, goName(value), typeName, value)
+		}
+		g.printf(")\n\n")
+	case len(t.Properties) > 0 || t.Type == "object" && t.Ref == "":
+		g.printf("type %s struct {\n", typeName)
+		required := map[string]bool{}
+		for _, name := range t.Required {
+			required[name] = true
+		}
+		for _, prop := range sortedKeys(t.Properties) {
+			p := t.Properties[prop]
+			g.doc(p.Description, "\t")
+			tag := prop
+			if p.Nullable || len(t.Required) > 0 && !required[prop] {
+				tag += ",omitempty"
+			}
+			g.printf("\t%s %s `json:%q`\n", goName(prop), g.goType(p), tag)
+		}
+		g.printf("}\n\n")
+	default:
+		g.printf("type %s %s\n\n", typeName, g.goType(t))
+	}
+}
+
+// goType returns the Go type used for values of type `t`.
+func (g *Generator) goType(t *Type) string {
+	var typ string
+	nillable := false
+
+	switch {
+	case t.Ref != "":
+		name, _ := g.schema.Resolve(t.Ref)
+		typ = goName(name)
+	case t.Type == "string":
+		if t.Format == "date-time" {
+			g.imports["time"] = true
+			typ = "time.Time"
+		} else {
+			typ = "string"
+		}
+	case t.Type == "integer":
+		if t.Format == "int32" {
+			typ = "int32"
+		} else {
+			typ = "int64"
+		}
+	case t.Type == "number":
+		if t.Format == "float" {
+			typ = "float32"
+		} else {
+			typ = "float64"
+		}
+	case t.Type == "boolean":
+		typ = "bool"
+	case t.Type == "buffer":
+		typ, nillable = "[]byte", true
+	case t.Type == "array":
+		typ, nillable = "[]"+g.goType(t.Items), true
+	case t.Type == "object":
+		typ, nillable = "map[string]any", true
+	default:
+		typ, nillable = "any", true
+	}
+
+	if t.Nullable && !nillable {
+		return "*" + typ
+	}
+	return typ
+}
+
+// encoding is how a function input or output is transferred.
+type encoding int
+
+const (
+	encodingJSON encoding = iota
+	encodingText
+	encodingBinary
+)
+
+func (g *Generator) encodingOf(p *Param) encoding {
+	mediaType, _, _ := strings.Cut(p.ContentType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case "application/json":
+		return encodingJSON
+	case "text/plain":
+		return encodingText
+	case "application/x-binary", "application/octet-stream":
+		return encodingBinary
+	}
+
+	t := &p.Type
+	if t.Ref != "" {
+		name, _ := g.schema.Resolve(t.Ref)
+		t = g.schema.Components.Schemas[name]
+	}
+	switch {
+	case t.Type == "buffer":
+		return encodingBinary
+	case t.Type == "string" && t.Format == "" && !t.Nullable:
+		return encodingText
+	default:
+		return encodingJSON
+	}
+}
+
+// contentType returns the content type of `p`, as declared by the schema or implied by its type.
+func (g *Generator) contentType(p *Param) string {
+	if p.ContentType != "" {
+		return p.ContentType
+	}
+	switch g.encodingOf(p) {
+	case encodingText:
+		return "text/plain"
+	case encodingBinary:
+		return "application/x-binary"
+	default:
+		return "application/json"
+	}
+}
+
+// description writes an init function describing the exports and imports of the schema to the
+// PDK, and the export sending the plugin description.
+func (g *Generator) description() {
+	g.imports[pdkImport] = true
+
+	g.printf("func init() {\n")
+	for _, name := range sortedKeys(g.schema.Exports) {
+		fn := g.schema.Exports[name]
+		g.printf("\tpdk.DescribeExport(pdk.ExportDescription{Name: %q", name)
+		if text := strings.TrimSpace(fn.Description); text != "" {
+			g.printf(", Description: %q", text)
+		}
+		if fn.Input != nil {
+			g.printf(", Input: %q", g.contentType(fn.Input))
+		}
+		if fn.Output != nil {
+			g.printf(", Output: %q", g.contentType(fn.Output))
+		}
+		g.printf("})\n")
+	}
+	for _, name := range sortedKeys(g.schema.Imports) {
+		g.printf("\tpdk.DescribeHostFunction(%q)\n", name)
+	}
+	g.printf("}\n\n")
+
+	g.printf("// exportDescribe is the %q export, sending the plugin description to the host.\n", describeExport)
+	g.printf("//\n")
+	g.printf("//go:wasmexport %s\n", describeExport)
+	g.printf("func exportDescribe() int32 {\n")
+	g.printf("\treturn pdk.OutputDescription()\n")
+	g.printf("}\n\n")
+}
+
+func (g *Generator) params(fn *Function) string {
+	if fn.Input == nil {
+		return ""
+	}
+	return "input " + g.goType(&fn.Input.Type)
+}
+
+func (g *Generator) results(fn *Function) string {
+	if fn.Output == nil {
+		return "error"
+	}
+	return "(" + g.goType(&fn.Output.Type) + ", error)"
+}
+
+func (g *Generator) export(name string, fn *Function) {
+	g.imports[pdkImport] = true
+	implName := goName(name)
+
+	g.printf("// export%s is the %q export, calling %s.\n", implName, name, implName)
+	g.printf("//\n")
+	g.printf("//go:wasmexport %s\n", name)
+	g.printf("func export%s() int32 {\n", implName)
+
+	call := implName + "()"
+	if fn.Input != nil {
+		typ := g.goType(&fn.Input.Type)
+		switch enc := g.encodingOf(fn.Input); {
+		case g.Validate && enc == encodingJSON:
+			g.printf("\tvar input %s\n", typ)
+			g.check(fmt.Sprintf("%s.InputJSON(%q, &input)", schemaVar, name))
+		case g.Validate && typ == "[]byte":
+			g.printf("\tinput, err := %s.Input(%q)\n", schemaVar, name)
+			g.checkErr()
+		case g.Validate:
+			g.printf("\tdata, err := %s.Input(%q)\n", schemaVar, name)
+			g.checkErr()
+			g.printf("\tinput := %s\n", convert(typ, "[]byte", "data"))
+		case enc == encodingText:
+			g.printf("\tinput := %s\n", convert(typ, "string", "pdk.InputString()"))
+		case enc == encodingBinary:
+			g.printf("\tinput := %s\n", convert(typ, "[]byte", "pdk.Input()"))
+		default:
+			g.printf("\tvar input %s\n", typ)
+			g.check("pdk.InputJSON(&input)")
+		}
+		call = implName + "(input)"
+		g.printf("\n")
+	}
+
+	if fn.Output == nil {
+		g.check(call)
+		g.printf("\treturn 0\n")
+		g.printf("}\n\n")
+		return
+	}
+
+	g.printf("\toutput, err := %s\n", call)
+	g.checkErr()
+	g.printf("\n")
+
+	typ := g.goType(&fn.Output.Type)
+	switch enc := g.encodingOf(fn.Output); {
+	case g.Validate && enc == encodingJSON:
+		g.check(fmt.Sprintf("%s.OutputJSON(%q, output)", schemaVar, name))
+	case g.Validate:
+		g.check(fmt.Sprintf("%s.Output(%q, %s)", schemaVar, name, convert("[]byte", typ, "output")))
+	case enc == encodingText:
+		g.printf("\tpdk.OutputString(%s)\n", convert("string", typ, "output"))
+	case enc == encodingBinary:
+		g.printf("\tpdk.Output(%s)\n", convert("[]byte", typ, "output"))
+	default:
+		g.check("pdk.OutputJSON(output)")
+	}
+	g.printf("\treturn 0\n")
+	g.printf("}\n\n")
+}
+
+// check writes a statement returning 1 from the export, after setting the host error, if `expr`
+// returns an error.
+func (g *Generator) check(expr string) {
+	g.printf("\tif err := %s; err != nil {\n", expr)
+	g.printf("\t\tpdk.SetError(err)\n")
+	g.printf("\t\treturn 1\n")
+	g.printf("\t}\n")
+}
+
+// checkErr is like `check`, for an `err` variable that has already been assigned.
+func (g *Generator) checkErr() {
+	g.printf("\tif err != nil {\n")
+	g.printf("\t\tpdk.SetError(err)\n")
+	g.printf("\t\treturn 1\n")
+	g.printf("\t}\n")
+}
+
+func (g *Generator) hostImport(name string, fn *Function) {
+	g.imports[pdkImport] = true
+	implName := goName(name)
+	hostName := "host" + implName
+
+	g.printf("//go:wasmimport extism:host/user %s\n", name)
+	switch {
+	case fn.Input != nil && fn.Output != nil:
+		g.printf("func %s(uint64) uint64\n\n", hostName)
+	case fn.Input != nil:
+		g.printf("func %s(uint64)\n\n", hostName)
+	case fn.Output != nil:
+		g.printf("func %s() uint64\n\n", hostName)
+	default:
+		g.printf("func %s()\n\n", hostName)
+	}
+
+	g.funcDoc(implName, fn.Description, fmt.Sprintf("calls the %q host function.", name))
+	g.printf("func %s(%s) %s {\n", implName, g.params(fn), g.results(fn))
+
+	in, arg := "[]byte", "nil"
+	if fn.Input != nil {
+		in, arg = g.goType(&fn.Input.Type), "input"
+	}
+	out := "[]byte"
+	if fn.Output != nil {
+		out = g.goType(&fn.Output.Type)
+	}
+
+	var call string
+	switch {
+	case fn.Input != nil && fn.Output != nil:
+		call = hostName
+	case fn.Input != nil:
+		call = "func(offset uint64) uint64 {\n" + hostName + "(offset)\nreturn 0\n}"
+	case fn.Output != nil:
+		call = "func(uint64) uint64 {\nreturn " + hostName + "()\n}"
+	default:
+		call = "func(uint64) uint64 {\n" + hostName + "()\nreturn 0\n}"
+	}
+
+	if fn.Output != nil {
+		g.printf("\treturn pdk.CallHost[%s, %s](%s, %s)\n", in, out, call, arg)
+	} else {
+		g.printf("\t_, err := pdk.CallHost[%s, %s](%s, %s)\n", in, out, call, arg)
+		g.printf("\treturn err\n")
+	}
+	g.printf("}\n\n")
+}
+
+// convert returns the expression `expr` of type `from` converted to type `to`.
+func convert(to, from, expr string) string {
+	if to == from {
+		return expr
+	}
+	return to + "(" + expr + ")"
+}
+
+// initialisms are words kept in upper case in Go identifiers.
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "JSON": true,
+	"URI": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts a schema name such as "count_vowels" or "userId" to an exported Go identifier.
+func goName(name string) string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = word[:0]
+		}
+	}
+
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+
+	var out strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			out.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		out.WriteRune(unicode.ToUpper(r[0]))
+		out.WriteString(string(r[1:]))
+	}
+
+	ident := out.String()
+	if ident == "" || unicode.IsDigit([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGenerateGolden compares the generated bindings and stubs with the golden files in testdata,
+// which `go test -update` rewrites.
+func TestGenerateGolden(t *testing.T) {
+	for _, test := range []struct {
+		schema, golden     string
+		validate, describe bool
+	}{
+		{filepath.Join("..", "..", "example-schema.yaml"), "example", false, false},
+		{filepath.Join("testdata", "features.yaml"), "features", false, false},
+		{filepath.Join("testdata", "features.yaml"), "features_validate", true, true},
+	} {
+		schema, err := LoadSchema(test.schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := NewGenerator(schema, "main")
+		g.Validate = test.validate
+		g.Describe = test.describe
+
+		bindings, err := g.Bindings()
+		if err != nil {
+			t.Fatalf("%s: %v", test.golden, err)
+		}
+		golden(t, filepath.Join("testdata", test.golden+".gen.go.golden"), bindings)
+
+		stubs, err := g.Stubs()
+		if err != nil {
+			t.Fatalf("%s: %v", test.golden, err)
+		}
+		golden(t, filepath.Join("testdata", test.golden+".stubs.go.golden"), stubs)
+	}
+}
+
+func golden(t *testing.T, path string, got []byte) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s differs from the generated code, run go test ./cmd/xtp-gen-go -update to update it:\n%s", path, got)
+	}
+}
+
+func TestLoadSchemaErrors(t *testing.T) {
+	for name, src := range map[string]string{
+		"version":   "version: v2\n",
+		"ref":       "version: v1\nexports:\n  f:\n    input:\n      $ref: \"#/components/schemas/Missing\"\n",
+		"items":     "version: v1\ncomponents:\n  schemas:\n    A:\n      type: array\n",
+		"type":      "version: v1\ncomponents:\n  schemas:\n    A:\n      type: tuple\n",
+		"undefined": "version: v1\nimports:\n  f:\n",
+	} {
+		path := filepath.Join(t.TempDir(), "schema.yaml")
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadSchema(path); err == nil {
+			t.Errorf("%s: no error loading an invalid schema", name)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/extism/go-pdk/cmd/xtp-gen-go
+
+go 1.21.1
+
+require gopkg.in/yaml.v3 v3.0.1
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// schemaLiteral writes the schema as a schema.Document variable, for the export wrappers to
+// validate their input and output against.
+func (g *Generator) schemaLiteral() {
+	g.imports[schemaImport] = true
+
+	g.printf("// %s is the schema the exports validate their input and output against.\n", schemaVar)
+	g.printf("var %s = &schema.Document{\n", schemaVar)
+	g.printf("Version: %q,\n", g.schema.Version)
+	g.functionsLiteral("Exports", g.schema.Exports)
+	g.functionsLiteral("Imports", g.schema.Imports)
+	if len(g.schema.Components.Schemas) > 0 {
+		g.printf("Components: schema.Components{\n")
+		g.printf("Schemas: map[string]*schema.Type{\n")
+		for _, name := range sortedKeys(g.schema.Components.Schemas) {
+			g.printf("%q: %s,\n", name, typeLiteral(g.schema.Components.Schemas[name], ""))
+		}
+		g.printf("},\n")
+		g.printf("},\n")
+	}
+	g.printf("}\n\n")
+}
+
+func (g *Generator) functionsLiteral(field string, fns map[string]*Function) {
+	if len(fns) == 0 {
+		return
+	}
+
+	g.printf("%s: map[string]*schema.Function{\n", field)
+	for _, name := range sortedKeys(fns) {
+		fn := fns[name]
+		g.printf("%q: {\n", name)
+		if fn.Input != nil {
+			g.printf("Input: %s,\n", paramLiteral(fn.Input))
+		}
+		if fn.Output != nil {
+			g.printf("Output: %s,\n", paramLiteral(fn.Output))
+		}
+		g.printf("},\n")
+	}
+	g.printf("},\n")
+}
+
+func paramLiteral(p *Param) string {
+	fields := []string{"Type: " + typeLiteral(&p.Type, "schema.Type")}
+	if p.ContentType != "" {
+		fields = append(fields, "ContentType: "+strconv.Quote(p.ContentType))
+	}
+	return "&schema.Param{\n" + strings.Join(fields, ",\n") + ",\n}"
+}
+
+// typeLiteral returns a schema.Type composite literal for `t`, of the literal type `typ` (which
+// is empty where it can be elided). Descriptions are left out, since they are not needed for
+// validation.
+func typeLiteral(t *Type, typ string) string {
+	var fields []string
+	add := func(name, value string) {
+		fields = append(fields, name+": "+value)
+	}
+
+	if t.Type != "" {
+		add("Type", strconv.Quote(t.Type))
+	}
+	if t.Format != "" {
+		add("Format", strconv.Quote(t.Format))
+	}
+	if t.Ref != "" {
+		add("Ref", strconv.Quote(t.Ref))
+	}
+	if t.Nullable {
+		add("Nullable", "true")
+	}
+	if t.Items != nil {
+		add("Items", typeLiteral(t.Items, "&schema.Type"))
+	}
+	if len(t.Properties) > 0 {
+		var props []string
+		for _, name := range sortedKeys(t.Properties) {
+			props = append(props, strconv.Quote(name)+": "+typeLiteral(t.Properties[name], ""))
+		}
+		add("Properties", "map[string]*schema.Type{\n"+strings.Join(props, ",\n")+",\n}")
+	}
+	if len(t.Required) > 0 {
+		add("Required", "[]string{"+quoteAll(t.Required)+"}")
+	}
+	if len(t.Enum) > 0 {
+		add("Enum", "[]string{"+quoteAll(t.Enum)+"}")
+	}
+
+	if len(fields) == 0 {
+		return typ + "{}"
+	}
+	return typ + "{\n" + strings.Join(fields, ",\n") + ",\n}"
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,81 @@
+// Command xtp-gen-go generates Go plugin bindings from an XTP schema, such as the
+// example-schema.yaml file at the root of this repository.
+//
+// It writes a single file containing:
+//   - a Go type for every component schema, with json tags (nullable properties become pointers),
+//   - an export wrapper for every export, decoding the input and encoding the output according to
+//     their content type, and calling a function of the same name that the plugin implements,
+//   - a typed wrapper for every import, calling the host function through pdk.CallHost.
+//
+// With -validate, the schema is embedded in the generated code as a schema.Document, and the
+// export wrappers validate their input and output against it (see package
+// github.com/extism/go-pdk/schema).
+//
+// With -describe, the generated code describes the exports and imports of the schema to the PDK,
+// and adds a "describe" export sending the plugin description to the host as JSON (see
+// pdk.Describe, which sets the plugin name, version and config keys).
+//
+// With -stubs, it also writes a file with unimplemented versions of the export functions, along
+// with an empty main function in package main, unless that file already exists.
+//
+// Usage:
+//
+//	xtp-gen-go -schema example-schema.yaml -o pdk.gen.go -stubs main.go
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+func main() {
+	schemaPath := flag.String("schema", "xtp-schema.yaml", "path to the XTP schema")
+	output := flag.String("o", "pdk.gen.go", "path of the generated bindings")
+	pkg := flag.String("package", "main", "package name of the generated code")
+	stubs := flag.String("stubs", "", "path of a file to create with unimplemented export functions")
+	validate := flag.Bool("validate", false, "validate export inputs and outputs against the schema")
+	describe := flag.Bool("describe", false, "add a describe export returning the plugin description")
+	flag.Parse()
+
+	if err := run(*schemaPath, *output, *pkg, *stubs, *validate, *describe); err != nil {
+		fmt.Fprintln(os.Stderr, "xtp-gen-go:", err)
+		os.Exit(1)
+	}
+}
+
+func run(schemaPath, output, pkg, stubs string, validate, describe bool) error {
+	schema, err := LoadSchema(schemaPath)
+	if err != nil {
+		return err
+	}
+
+	g := NewGenerator(schema, pkg)
+	g.Validate = validate
+	g.Describe = describe
+
+	bindings, err := g.Bindings()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, bindings, 0o644); err != nil {
+		return err
+	}
+
+	if stubs == "" {
+		return nil
+	}
+	if _, err := os.Stat(stubs); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	code, err := g.Stubs()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(stubs, code, 0o644)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema is an XTP schema, as described at https://docs.xtp.dylibso.com/docs/concepts/xtp-schema.
+type Schema struct {
+	Version    string               `yaml:"version"`
+	Exports    map[string]*Function `yaml:"exports"`
+	Imports    map[string]*Function `yaml:"imports"`
+	Components struct {
+		Schemas map[string]*Type `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// Function is an export implemented by the plugin, or an import provided by the host.
+type Function struct {
+	Description string `yaml:"description"`
+	Input       *Param `yaml:"input"`
+	Output      *Param `yaml:"output"`
+}
+
+// Param is the input or output of a `Function`.
+type Param struct {
+	Type        `yaml:",inline"`
+	ContentType string `yaml:"contentType"`
+}
+
+// Type describes a value: either a primitive, an array, a free-form object, a reference to a
+// component schema, or (for component schemas) an object with properties or a string enum.
+type Type struct {
+	Description string           `yaml:"description"`
+	Type        string           `yaml:"type"`
+	Format      string           `yaml:"format"`
+	Ref         string           `yaml:"$ref"`
+	Nullable    bool             `yaml:"nullable"`
+	Items       *Type            `yaml:"items"`
+	Properties  map[string]*Type `yaml:"properties"`
+	Required    []string         `yaml:"required"`
+	Enum        []string         `yaml:"enum"`
+}
+
+// LoadSchema reads and validates the XTP schema at `path`.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if !strings.HasPrefix(schema.Version, "v1") {
+		return nil, fmt.Errorf("unsupported schema version %q", schema.Version)
+	}
+
+	for _, name := range sortedKeys(schema.Exports) {
+		if err := schema.checkFunction("export", name, schema.Exports[name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(schema.Imports) {
+		if err := schema.checkFunction("import", name, schema.Imports[name]); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range sortedKeys(schema.Components.Schemas) {
+		if err := schema.checkType("schema "+name, schema.Components.Schemas[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &schema, nil
+}
+
+func (s *Schema) checkFunction(kind, name string, fn *Function) error {
+	if fn == nil {
+		return fmt.Errorf("%s %s: missing definition", kind, name)
+	}
+	if fn.Input != nil {
+		if err := s.checkType(kind+" "+name+" input", &fn.Input.Type); err != nil {
+			return err
+		}
+	}
+	if fn.Output != nil {
+		if err := s.checkType(kind+" "+name+" output", &fn.Output.Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Schema) checkType(where string, t *Type) error {
+	if t == nil {
+		return fmt.Errorf("%s: missing type", where)
+	}
+	if t.Ref != "" {
+		if _, err := s.Resolve(t.Ref); err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+		return nil
+	}
+	switch t.Type {
+	case "string", "integer", "number", "boolean", "buffer", "object", "":
+	case "array":
+		if t.Items == nil {
+			return fmt.Errorf("%s: array without items", where)
+		}
+		return s.checkType(where+" items", t.Items)
+	default:
+		return fmt.Errorf("%s: unsupported type %q", where, t.Type)
+	}
+	for _, name := range sortedKeys(t.Properties) {
+		if err := s.checkType(where+"."+name, t.Properties[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Resolve returns the name of the component schema referenced by `ref`.
+func (s *Schema) Resolve(ref string) (string, error) {
+	name, ok := strings.CutPrefix(ref, "#/components/schemas/")
+	if !ok {
+		return "", fmt.Errorf("unsupported reference %q", ref)
+	}
+	if _, ok := s.Components.Schemas[name]; !ok {
+		return "", fmt.Errorf("reference to unknown schema %q", name)
+	}
+	return name, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
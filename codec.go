@@ -0,0 +1,164 @@
+package pdk
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+)
+
+// DecodeError is returned when a host memory block cannot be decoded into the requested type.
+type DecodeError struct {
+	// Type is the name of the type being decoded, or "JSON" for JSON-encoded values.
+	Type string
+	// Length is the number of bytes in the memory block.
+	Length int
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return "cannot decode " + strconv.Itoa(e.Length) + " bytes as " + e.Type + ": " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// encodeValue encodes `v` into the bytes stored in a host memory block. Strings and byte slices
+// are stored as-is, booleans as a single byte, fixed-size numbers in little-endian (like `ResultU32`
+// and `ResultU64`, with `int` and `uint` taking 8 bytes), and any other value as JSON.
+func encodeValue(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case bool:
+		if v {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+	case int8:
+		return []byte{byte(v)}, nil
+	case uint8:
+		return []byte{v}, nil
+	case int16:
+		return binary.LittleEndian.AppendUint16(nil, uint16(v)), nil
+	case uint16:
+		return binary.LittleEndian.AppendUint16(nil, v), nil
+	case int32:
+		return binary.LittleEndian.AppendUint32(nil, uint32(v)), nil
+	case uint32:
+		return binary.LittleEndian.AppendUint32(nil, v), nil
+	case int64:
+		return binary.LittleEndian.AppendUint64(nil, uint64(v)), nil
+	case uint64:
+		return binary.LittleEndian.AppendUint64(nil, v), nil
+	case int:
+		return binary.LittleEndian.AppendUint64(nil, uint64(v)), nil
+	case uint:
+		return binary.LittleEndian.AppendUint64(nil, uint64(v)), nil
+	case float32:
+		return binary.LittleEndian.AppendUint32(nil, math.Float32bits(v)), nil
+	case float64:
+		return binary.LittleEndian.AppendUint64(nil, math.Float64bits(v)), nil
+	default:
+		return marshalJSON(v)
+	}
+}
+
+// decodeValue decodes `data` into the value pointed to by `v`, using the encoding of `encodeValue`.
+// Fixed-size values must be stored in a block of exactly their size.
+func decodeValue(data []byte, v any) error {
+	switch v := v.(type) {
+	case *[]byte:
+		*v = data
+	case *string:
+		*v = string(data)
+	case *bool:
+		if err := checkSize(data, 1, "bool"); err != nil {
+			return err
+		}
+		*v = data[0] != 0
+	case *int8:
+		if err := checkSize(data, 1, "int8"); err != nil {
+			return err
+		}
+		*v = int8(data[0])
+	case *uint8:
+		if err := checkSize(data, 1, "uint8"); err != nil {
+			return err
+		}
+		*v = data[0]
+	case *int16:
+		if err := checkSize(data, 2, "int16"); err != nil {
+			return err
+		}
+		*v = int16(binary.LittleEndian.Uint16(data))
+	case *uint16:
+		if err := checkSize(data, 2, "uint16"); err != nil {
+			return err
+		}
+		*v = binary.LittleEndian.Uint16(data)
+	case *int32:
+		if err := checkSize(data, 4, "int32"); err != nil {
+			return err
+		}
+		*v = int32(binary.LittleEndian.Uint32(data))
+	case *uint32:
+		if err := checkSize(data, 4, "uint32"); err != nil {
+			return err
+		}
+		*v = binary.LittleEndian.Uint32(data)
+	case *int64:
+		if err := checkSize(data, 8, "int64"); err != nil {
+			return err
+		}
+		*v = int64(binary.LittleEndian.Uint64(data))
+	case *uint64:
+		if err := checkSize(data, 8, "uint64"); err != nil {
+			return err
+		}
+		*v = binary.LittleEndian.Uint64(data)
+	case *int:
+		if err := checkSize(data, 8, "int"); err != nil {
+			return err
+		}
+		*v = int(binary.LittleEndian.Uint64(data))
+	case *uint:
+		if err := checkSize(data, 8, "uint"); err != nil {
+			return err
+		}
+		*v = uint(binary.LittleEndian.Uint64(data))
+	case *float32:
+		if err := checkSize(data, 4, "float32"); err != nil {
+			return err
+		}
+		*v = math.Float32frombits(binary.LittleEndian.Uint32(data))
+	case *float64:
+		if err := checkSize(data, 8, "float64"); err != nil {
+			return err
+		}
+		*v = math.Float64frombits(binary.LittleEndian.Uint64(data))
+	default:
+		if len(data) == 0 {
+			return &DecodeError{Type: "JSON", Length: 0, Err: errors.New("empty memory block")}
+		}
+		if err := unmarshalJSON(data, v); err != nil {
+			return &DecodeError{Type: "JSON", Length: len(data), Err: err}
+		}
+	}
+	return nil
+}
+
+func checkSize(data []byte, size int, typ string) error {
+	if len(data) == size {
+		return nil
+	}
+	return &DecodeError{
+		Type:   typ,
+		Length: len(data),
+		Err:    errors.New("expected " + strconv.Itoa(size) + " bytes"),
+	}
+}
@@ -0,0 +1,83 @@
+package pdk
+
+import (
+	"errors"
+	"strings"
+)
+
+// Codec encodes and decodes envelope payloads of a given content type.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// codecs holds the codecs by media type. JSON codecs are also used for "+json" media types, such
+// as "application/problem+json".
+var codecs = map[string]Codec{
+	"application/json":         jsonCodec{},
+	"text/plain":               textCodec{},
+	"application/octet-stream": textCodec{},
+}
+
+// RegisterCodec registers `codec` for the media type `mediaType`, e.g. "application/cbor",
+// replacing any codec previously registered for it.
+func RegisterCodec(mediaType string, codec Codec) {
+	codecs[strings.ToLower(mediaType)] = codec
+}
+
+// codecFor returns the codec for `contentType`, whose media type parameters are ignored.
+func codecFor(contentType string) (Codec, error) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if mediaType == "" {
+		mediaType = "application/octet-stream"
+	}
+
+	if codec, ok := codecs[mediaType]; ok {
+		return codec, nil
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		return codecs["application/json"], nil
+	}
+	return nil, errors.New("no codec registered for content type " + mediaType)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return marshalJSON(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return unmarshalJSON(data, v)
+}
+
+// textCodec passes text and binary payloads through, to and from strings and byte slices.
+type textCodec struct{}
+
+func (textCodec) Marshal(v any) ([]byte, error) {
+	switch v := v.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case *[]byte:
+		return *v, nil
+	case *string:
+		return []byte(*v), nil
+	default:
+		return nil, errors.New("text and binary payloads can only be encoded from a string or []byte")
+	}
+}
+
+func (textCodec) Unmarshal(data []byte, v any) error {
+	switch v := v.(type) {
+	case *[]byte:
+		*v = data
+	case *string:
+		*v = string(data)
+	default:
+		return errors.New("text and binary payloads can only be decoded into a *string or *[]byte")
+	}
+	return nil
+}
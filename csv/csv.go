@@ -0,0 +1,55 @@
+// Package csv reads the CSV input of a plugin record by record. It is separate from the core
+// package, so that plugins which don't read CSV don't include encoding/csv and the fmt package it
+// depends on.
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+
+	pdk "github.com/extism/go-pdk"
+)
+
+// Input returns an iterator over the records of the CSV input, read by an encoding/csv reader
+// which the `options` functions can configure, e.g. to change the separator:
+//
+//	for record, err := range pdkcsv.Input(func(r *csv.Reader) { r.Comma = ';' }) {
+//		if err != nil {
+//			pdk.SetError(err)
+//			return 1
+//		}
+//		pdk.Log(pdk.LogDebug, strings.Join(record.Value, ","))
+//	}
+//
+// The input is read as the iteration goes, through pdk.InputReader. A malformed record yields a
+// csv.ParseError, along with the fields read if it has the wrong number of fields, and the
+// iteration goes on with the next record unless the loop breaks.
+func Input(options ...func(*csv.Reader)) func(yield func(pdk.Record[[]string], error) bool) {
+	return func(yield func(pdk.Record[[]string], error) bool) {
+		r := csv.NewReader(pdk.InputReader())
+		for _, option := range options {
+			option(r)
+		}
+		for {
+			fields, err := r.Read()
+			if err == io.EOF {
+				return
+			}
+			record := pdk.Record[[]string]{Value: fields}
+			var parseErr *csv.ParseError
+			switch {
+			case err == nil:
+				record.Line, _ = r.FieldPos(0)
+			case errors.As(err, &parseErr):
+				record.Line = parseErr.StartLine
+			default:
+				yield(record, err)
+				return
+			}
+			if !yield(record, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,54 @@
+//go:build !wasm
+
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"reflect"
+	"testing"
+
+	pdk "github.com/extism/go-pdk"
+	"github.com/extism/go-pdk/native"
+)
+
+func collect(options ...func(*csv.Reader)) ([]pdk.Record[[]string], []error) {
+	var records []pdk.Record[[]string]
+	var errs []error
+	Input(options...)(func(record pdk.Record[[]string], err error) bool {
+		records = append(records, record)
+		errs = append(errs, err)
+		return true
+	})
+	return records, errs
+}
+
+func TestInput(t *testing.T) {
+	native.Call([]byte("a;b\n\"multi\nline\";c\nd;e"), func() int32 {
+		records, errs := collect(func(r *csv.Reader) { r.Comma = ';' })
+		want := []pdk.Record[[]string]{{Line: 1, Value: []string{"a", "b"}}, {Line: 2, Value: []string{"multi\nline", "c"}}, {Line: 4, Value: []string{"d", "e"}}}
+		if !reflect.DeepEqual(records, want) {
+			t.Errorf("Input() = %q, want %q", records, want)
+		}
+		for _, err := range errs {
+			if err != nil {
+				t.Errorf("Input() error: %v", err)
+			}
+		}
+		return 0
+	})
+}
+
+func TestInputParseError(t *testing.T) {
+	native.Call([]byte("a,b\nc\nd,e\n"), func() int32 {
+		records, errs := collect()
+		if len(records) != 3 || records[2].Value[0] != "d" {
+			t.Fatalf("Input() = %q, want 3 records", records)
+		}
+		var parseErr *csv.ParseError
+		if !errors.As(errs[1], &parseErr) || records[1].Line != 2 || !reflect.DeepEqual(records[1].Value, []string{"c"}) {
+			t.Errorf("record 2 = %q, %v, want the fields of line 2 and a csv.ParseError", records[1], errs[1])
+		}
+		return 0
+	})
+}
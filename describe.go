@@ -0,0 +1,212 @@
+package pdk
+
+import (
+	"sort"
+
+	"github.com/extism/go-pdk/jsonlite"
+)
+
+// DescribeExportName is the name of the export registered by `Describe`.
+const DescribeExportName = "describe"
+
+// Description describes a plugin to its host: the exports it offers, the config keys it reads and
+// what it needs from the host. See `Describe`.
+type Description struct {
+	Name    string
+	Version string
+	// Exports are merged with those described by `DescribeExport`, and those registered with
+	// `Register`.
+	Exports []ExportDescription
+	Config  []ConfigDescription
+	// HostFunctions are the names of the host functions the plugin imports, merged with those
+	// described by `DescribeHostFunction`.
+	HostFunctions []string
+	// AllowedHosts are the hosts the plugin sends HTTP requests to, which the host must allow.
+	AllowedHosts []string
+}
+
+// ExportDescription describes an export of the plugin.
+type ExportDescription struct {
+	Name        string
+	Description string
+	// Input and Output are the content types of the export input and output, such as
+	// "application/json", or "" if it takes no input or has no output.
+	Input  string
+	Output string
+}
+
+// ConfigDescription describes a config key read by the plugin.
+type ConfigDescription struct {
+	Key         string
+	Description string
+	Required    bool
+}
+
+var description struct {
+	Description
+	exports       map[string]ExportDescription
+	hostFunctions map[string]bool
+}
+
+// Describe sets the description of the plugin, and registers the `DescribeExportName` export
+// sending it to the host as JSON (see `OutputDescription`):
+//
+//	func init() {
+//		pdk.Describe(pdk.Description{
+//			Name:    "count-vowels",
+//			Version: "1.2.0",
+//			Config:  []pdk.ConfigDescription{{Key: "vowels", Description: "the letters counted"}},
+//		})
+//	}
+//
+// Modules built with the standard Go compiler, which dispatch to their exports with `Run`, need
+// nothing more. Others must declare the export themselves:
+//
+//	//go:wasmexport describe
+//	func describe() int32 {
+//		return pdk.OutputDescription()
+//	}
+func Describe(d Description) {
+	description.Description = d
+	Register(DescribeExportName, OutputDescription)
+}
+
+// DescribeExport adds `e` to the exports of the plugin description, such as those generated by
+// xtp-gen-go with the -describe flag.
+func DescribeExport(e ExportDescription) {
+	if description.exports == nil {
+		description.exports = map[string]ExportDescription{}
+	}
+	description.exports[e.Name] = e
+}
+
+// DescribeHostFunction adds the host function `name` to the plugin description.
+func DescribeHostFunction(name string) {
+	if description.hostFunctions == nil {
+		description.hostFunctions = map[string]bool{}
+	}
+	description.hostFunctions[name] = true
+}
+
+// OutputDescription sends the plugin description to the host as JSON, and returns 0, or 1 after
+// setting the error if it can't be encoded:
+//
+//	{"name":"count-vowels","version":"1.2.0",
+//	 "exports":[{"name":"count_vowels","input":"text/plain","output":"application/json"}],
+//	 "config":[{"key":"vowels","description":"the letters counted","required":false}]}
+//
+// Its exports are those of `Description.Exports`, completed by those described by
+// `DescribeExport` and registered with `Register`, sorted by name.
+func OutputDescription() int32 {
+	data, err := describe().MarshalJSON()
+	if err != nil {
+		SetError(err)
+		return 1
+	}
+	Output(data)
+	return 0
+}
+
+// describe returns the description set by `Describe`, merged with the other registrations.
+func describe() *Description {
+	d := description.Description
+
+	described := map[string]bool{}
+	for _, e := range d.Exports {
+		described[e.Name] = true
+	}
+	list := append([]ExportDescription(nil), d.Exports...)
+	for name, e := range description.exports {
+		if !described[name] {
+			list = append(list, e)
+			described[name] = true
+		}
+	}
+	for name := range exports {
+		if !described[name] {
+			e := ExportDescription{Name: name}
+			if name == DescribeExportName {
+				e.Description = "Describes the plugin"
+				e.Output = "application/json"
+			}
+			list = append(list, e)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	d.Exports = list
+
+	hosts := map[string]bool{}
+	for _, name := range d.HostFunctions {
+		hosts[name] = true
+	}
+	d.HostFunctions = append([]string(nil), d.HostFunctions...)
+	for _, name := range jsonlite.SortedKeys(description.hostFunctions) {
+		if !hosts[name] {
+			d.HostFunctions = append(d.HostFunctions, name)
+		}
+	}
+	return &d
+}
+
+// MarshalJSON encodes the description as sent by `OutputDescription`.
+func (d *Description) MarshalJSON() ([]byte, error) {
+	e := jsonlite.NewEncoder()
+	e.BeginObject()
+	e.Key("name")
+	e.String(d.Name)
+	if d.Version != "" {
+		e.Key("version")
+		e.String(d.Version)
+	}
+
+	e.Key("exports")
+	e.BeginArray()
+	for _, export := range d.Exports {
+		e.BeginObject()
+		e.Key("name")
+		e.String(export.Name)
+		optionalString(e, "description", export.Description)
+		optionalString(e, "input", export.Input)
+		optionalString(e, "output", export.Output)
+		e.EndObject()
+	}
+	e.EndArray()
+
+	if len(d.Config) > 0 {
+		e.Key("config")
+		e.BeginArray()
+		for _, config := range d.Config {
+			e.BeginObject()
+			e.Key("key")
+			e.String(config.Key)
+			optionalString(e, "description", config.Description)
+			e.Key("required")
+			e.Bool(config.Required)
+			e.EndObject()
+		}
+		e.EndArray()
+	}
+	stringArray(e, "hostFunctions", d.HostFunctions)
+	stringArray(e, "allowedHosts", d.AllowedHosts)
+	e.EndObject()
+	return e.Bytes()
+}
+
+func optionalString(e *jsonlite.Encoder, key, value string) {
+	if value != "" {
+		e.Key(key)
+		e.String(value)
+	}
+}
+
+func stringArray(e *jsonlite.Encoder, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	e.Key(key)
+	e.BeginArray()
+	for _, value := range values {
+		e.String(value)
+	}
+	e.EndArray()
+}
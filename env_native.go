@@ -0,0 +1,77 @@
+//go:build !wasm
+
+package pdk
+
+import (
+	"github.com/extism/go-pdk/internal/host"
+	"github.com/extism/go-pdk/internal/memory"
+)
+
+// The host functions of env.go, implemented by the local host of native builds.
+
+func extismInputLength() uint64 {
+	return host.InputLength()
+}
+
+func extismInputLoadU8(offset memory.ExtismPointer) uint8 {
+	return host.InputLoadU8(uint64(offset))
+}
+
+func extismInputLoadU64(offset memory.ExtismPointer) uint64 {
+	return host.InputLoadU64(uint64(offset))
+}
+
+func extismOutputSet(offset memory.ExtismPointer, length uint64) {
+	host.OutputSet(uint64(offset), length)
+}
+
+func extismErrorSet(offset memory.ExtismPointer) {
+	host.ErrorSet(uint64(offset))
+}
+
+func extismConfigGet(offset memory.ExtismPointer) memory.ExtismPointer {
+	return memory.ExtismPointer(host.ConfigGet(uint64(offset)))
+}
+
+func extismVarGet(offset memory.ExtismPointer) memory.ExtismPointer {
+	return memory.ExtismPointer(host.VarGet(uint64(offset)))
+}
+
+func extismVarSet(offset, valueOffset memory.ExtismPointer) {
+	host.VarSet(uint64(offset), uint64(valueOffset))
+}
+
+func extismLogInfo(offset memory.ExtismPointer) {
+	host.Log(int32(LogInfo), uint64(offset))
+}
+
+func extismLogDebug(offset memory.ExtismPointer) {
+	host.Log(int32(LogDebug), uint64(offset))
+}
+
+func extismLogWarn(offset memory.ExtismPointer) {
+	host.Log(int32(LogWarn), uint64(offset))
+}
+
+func extismLogError(offset memory.ExtismPointer) {
+	host.Log(int32(LogError), uint64(offset))
+}
+
+func extismLogTrace(offset memory.ExtismPointer) {
+	host.Log(int32(LogTrace), uint64(offset))
+}
+
+func extismGetLogLevel() int32 {
+	return host.LogLevel()
+}
+
+// start configures the local host from the command-line flags, unless the native package
+// configured it.
+func start() {
+	host.Start()
+}
+
+// exit flushes the output, error and vars of the local host, then exits with `code`.
+func exit(code int32) {
+	host.Exit(code)
+}
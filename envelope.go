@@ -0,0 +1,242 @@
+package pdk
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/extism/go-pdk/internal/memory"
+)
+
+// envelopeMagic is the first line of an envelope.
+const envelopeMagic = "extism-envelope/1"
+
+// Envelope headers with a dedicated `Envelope` field.
+const (
+	contentTypeHeader = "Content-Type"
+	requestIDHeader   = "Request-Id"
+	callerHeader      = "Caller"
+)
+
+// Envelope is a payload along with metadata about it. Hosts may send the plugin input as an
+// envelope, which is text made of a first "extism-envelope/1" line, "Name: value" header lines,
+// an empty line, and the payload:
+//
+//	extism-envelope/1
+//	Content-Type: application/json
+//	Request-Id: 6f1c2a
+//	Caller: billing-service
+//	Tenant: acme
+//
+//	{"input": "this is a test"}
+//
+// Header names are case-insensitive. The empty line may be left out when there is no payload.
+type Envelope struct {
+	// ContentType is the media type of the payload, from the "Content-Type" header.
+	ContentType string
+	// RequestID correlates the call with the host request, from the "Request-Id" header.
+	RequestID string
+	// Caller identifies the caller on the host side, from the "Caller" header.
+	Caller string
+	// Metadata holds the other headers, keyed by their canonical name (e.g. "Tenant").
+	Metadata map[string]string
+	// Payload is the data following the headers.
+	Payload []byte
+}
+
+// InputEnvelope returns the envelope sent as the host input, or false if the input is not an
+// envelope. Once it has returned true, `Input` and the other input functions return the envelope
+// payload, for as long as the host input stays the same.
+func InputEnvelope() (Envelope, bool) {
+	input.raw, input.envelope = nil, nil
+	// check the first line before loading the input, which streaming plugins don't hold whole
+	if !inputHasPrefix(envelopeMagic) {
+		return Envelope{}, false
+	}
+
+	data := loadInput()
+	headers, payload, ok := parseEnvelope(data)
+	if !ok {
+		return Envelope{}, false
+	}
+	input.raw = data
+	input.envelope = newEnvelope(headers, payload)
+	return *input.envelope, true
+}
+
+// OutputEnvelope sends `e` to the host output, in the envelope format.
+func OutputEnvelope(e Envelope) {
+	Output(e.Bytes())
+}
+
+// NewEnvelope returns an envelope whose payload is `v` encoded with the `Codec` registered for
+// `contentType`.
+func NewEnvelope(contentType string, v any) (Envelope, error) {
+	codec, err := codecFor(contentType)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	payload, err := codec.Marshal(v)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	return Envelope{ContentType: contentType, Payload: payload}, nil
+}
+
+// Decode decodes the payload into `v` with the `Codec` registered for the envelope content type.
+// An envelope without content type is decoded as "application/octet-stream".
+func (e Envelope) Decode(v any) error {
+	codec, err := codecFor(e.ContentType)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(e.Payload, v)
+}
+
+// Reply returns an envelope answering `e`, with the same request ID and `v` as payload. The payload
+// is encoded in the first media type of the "Accept" metadata header that has a registered `Codec`,
+// or else in the content type of `e`.
+func (e Envelope) Reply(v any) (Envelope, error) {
+	contentType := e.ContentType
+	for _, accepted := range strings.Split(e.Metadata["Accept"], ",") {
+		accepted = strings.TrimSpace(accepted)
+		if _, err := codecFor(accepted); accepted != "" && err == nil {
+			contentType = accepted
+			break
+		}
+	}
+
+	reply, err := NewEnvelope(contentType, v)
+	if err != nil {
+		return Envelope{}, err
+	}
+	reply.RequestID = e.RequestID
+	return reply, nil
+}
+
+// Bytes returns the envelope in the envelope format. Line breaks in header values are replaced by
+// spaces.
+func (e Envelope) Bytes() []byte {
+	var buf bytes.Buffer
+	buf.WriteString(envelopeMagic + "\n")
+
+	writeHeader := func(name, value string) {
+		if value == "" {
+			return
+		}
+		value = strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+		buf.WriteString(canonicalHeaderKey(name) + ": " + value + "\n")
+	}
+
+	writeHeader(contentTypeHeader, e.ContentType)
+	writeHeader(requestIDHeader, e.RequestID)
+	writeHeader(callerHeader, e.Caller)
+
+	names := make([]string, 0, len(e.Metadata))
+	for name := range e.Metadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeHeader(name, e.Metadata[name])
+	}
+
+	buf.WriteString("\n")
+	buf.Write(e.Payload)
+	return buf.Bytes()
+}
+
+func newEnvelope(headers map[string]string, payload []byte) *Envelope {
+	e := &Envelope{
+		ContentType: headers[contentTypeHeader],
+		RequestID:   headers[requestIDHeader],
+		Caller:      headers[callerHeader],
+		Metadata:    map[string]string{},
+		Payload:     payload,
+	}
+	for name, value := range headers {
+		switch name {
+		case contentTypeHeader, requestIDHeader, callerHeader:
+		default:
+			e.Metadata[name] = value
+		}
+	}
+	return e
+}
+
+// inputHasPrefix reports whether the host input starts with `prefix`, reading no more of it.
+func inputHasPrefix(prefix string) bool {
+	if extismInputLength() < uint64(len(prefix)) {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		if extismInputLoadU8(memory.ExtismPointer(i)) != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseEnvelope splits an input envelope into its headers and payload. An envelope is the
+// `envelopeMagic` line, followed by "Name: value" header lines, an empty line, and the payload:
+//
+//	extism-envelope/1
+//	Export: count_vowels
+//
+//	this is a test
+//
+// Lines may end with "\n" or "\r\n", and the empty line may be left out when there is no payload.
+// Header names are canonicalized like HTTP header names. It returns false if `data` is not an
+// envelope.
+func parseEnvelope(data []byte) (map[string]string, []byte, bool) {
+	line, rest, ok := cutLine(data)
+	if !ok || string(line) != envelopeMagic {
+		return nil, nil, false
+	}
+
+	headers := map[string]string{}
+	for len(rest) > 0 {
+		var next []byte
+		line, next, ok = cutLine(rest)
+		if !ok {
+			// the envelope ends with its headers, and has no payload
+			line = bytes.TrimSuffix(rest, []byte{'\r'})
+		}
+		rest = next
+		if len(line) == 0 {
+			return headers, rest, true
+		}
+
+		name, value, found := strings.Cut(string(line), ":")
+		if !found {
+			return nil, nil, false
+		}
+		headers[canonicalHeaderKey(strings.TrimSpace(name))] = strings.TrimSpace(value)
+	}
+	return headers, nil, true
+}
+
+// cutLine returns the first line of `data` without its line ending, and the data following it.
+func cutLine(data []byte) ([]byte, []byte, bool) {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return nil, nil, false
+	}
+	return bytes.TrimSuffix(data[:i], []byte{'\r'}), data[i+1:], true
+}
+
+// canonicalHeaderKey returns `key` with the first letter and any letter following a hyphen in
+// upper case, and the rest in lower case, e.g. "content-type" becomes "Content-Type".
+func canonicalHeaderKey(key string) string {
+	b := []byte(strings.ToLower(key))
+	upper := true
+	for i, c := range b {
+		if upper && 'a' <= c && c <= 'z' {
+			b[i] = c - 'a' + 'A'
+		}
+		upper = c == '-'
+	}
+	return string(b)
+}
@@ -0,0 +1,78 @@
+//go:build !wasm
+
+package pdk
+
+import (
+	"io"
+	"testing"
+
+	"github.com/extism/go-pdk/native"
+)
+
+func TestInputEnvelopeCalls(t *testing.T) {
+	calls := []struct {
+		input     string
+		envelope  bool
+		requestID string
+		payload   string
+	}{
+		{"extism-envelope/1\nRequest-Id: 1\n\nfirst", true, "1", "first"},
+		{"extism-envelope/1\nRequest-Id: 2\n\nsecond", true, "2", "second"},
+		{"plain", false, "", "plain"},
+		{"", false, "", ""},
+		{"extism-env", false, "", "extism-env"},
+		{"extism-envelope/2\n\nnewer", false, "", "extism-envelope/2\n\nnewer"},
+		{"extism-envelope/1\n", true, "", ""},
+		{"extism-envelope/1\nRequest-Id: 3\n\nthird", true, "3", "third"},
+	}
+
+	for _, call := range calls {
+		_, _, _ = native.Call([]byte(call.input), func() int32 {
+			envelope, ok := InputEnvelope()
+			if ok != call.envelope || envelope.RequestID != call.requestID {
+				t.Errorf("%q: InputEnvelope() = %q, %v, want %q, %v", call.input, envelope.RequestID, ok, call.requestID, call.envelope)
+			}
+			if got := InputString(); got != call.payload {
+				t.Errorf("%q: InputString() = %q, want %q", call.input, got, call.payload)
+			}
+			if got, _ := io.ReadAll(InputReader()); string(got) != call.payload {
+				t.Errorf("%q: InputReader() read %q, want %q", call.input, got, call.payload)
+			}
+			return 0
+		})
+	}
+}
+
+func TestInputAfterEnvelopeCall(t *testing.T) {
+	native.Call([]byte("extism-envelope/1\nRequest-Id: 1\n\nfirst"), func() int32 {
+		InputEnvelope()
+		return 0
+	})
+
+	// a call that doesn't check for an envelope sees its own input
+	native.Call([]byte("second"), func() int32 {
+		if got := InputString(); got != "second" {
+			t.Errorf("InputString() = %q, want second", got)
+		}
+		return 0
+	})
+}
+
+func TestRunDispatchCalls(t *testing.T) {
+	defer func() { exports = map[string]func() int32{} }()
+	Register("a", func() int32 {
+		OutputString("a:" + InputString())
+		return 0
+	})
+	Register("b", func() int32 {
+		OutputString("b:" + InputString())
+		return 0
+	})
+
+	for _, name := range []string{"a", "b", "a"} {
+		output, errMsg, code := native.Call([]byte("extism-envelope/1\nExport: "+name+"\n\n"+name+"-input"), dispatch)
+		if want := name + ":" + name + "-input"; code != 0 || string(output) != want {
+			t.Errorf("dispatch to %s = %q (%d, %q), want %q", name, output, code, errMsg, want)
+		}
+	}
+}
@@ -11,13 +11,15 @@ import (
 )
 
 // Currently, the standard Go compiler cannot export custom functions and is limited to exporting
-// `_start` via WASI. So, `main` functions should contain the plugin behavior, that the host will
-// invoke by explicitly calling `_start`.
+// `_start` via WASI. So, `main` registers the plugin exports, and `pdk.Run` calls the one selected
+// by the host (through the `extism_export` config key or an input envelope) when the host invokes
+// `_start`.
 func main() {
-	countVowels()
-	countVowelsTyped()
-	countVowelsJSONOutput()
-	countVowelsJSONRoundtripMem()
+	pdk.Register("count_vowels", countVowels)
+	pdk.Register("count_vowels_typed", countVowelsTyped)
+	pdk.Register("count_vowels_json_output", countVowelsJSONOutput)
+	pdk.Register("count_vowels_roundtrip_json_mem", countVowelsJSONRoundtripMem)
+	pdk.Run()
 }
 
 // CountVowelsInput represents the JSON input provided by the host.
@@ -63,6 +65,7 @@ func countVowelsJSONRoundtripMem() int32 {
 		pdk.SetError(err)
 		return -1
 	}
+	defer mem.Free()
 
 	// find the data in mem and ensure it's the same once decoded
 	var b CountVowelsOuptut
@@ -52,6 +52,7 @@ func countVowelsJSONRoundtripMem() int32 {
 		pdk.SetError(err)
 		return -1
 	}
+	defer mem.Free()
 
 	// find the data in mem and ensure it's the same once decoded
 	var b CountVowelsOuptut
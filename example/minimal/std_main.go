@@ -0,0 +1,20 @@
+//go:build std
+// +build std
+
+package main
+
+import (
+	"github.com/extism/go-pdk"
+)
+
+// The smallest useful plugin, built by `make size` to keep track of the size the PDK adds to a
+// plugin.
+func main() {
+	pdk.Register("greet", greet)
+	pdk.Run()
+}
+
+func greet() int32 {
+	pdk.OutputString("Hello, " + pdk.InputString() + "!")
+	return 0
+}
@@ -0,0 +1,11 @@
+//go:build wasm
+
+package pdk
+
+import "os"
+
+func start() {}
+
+func exit(code int32) {
+	os.Exit(int(code))
+}
@@ -0,0 +1,93 @@
+package pdk
+
+import (
+	"sort"
+	"strings"
+)
+
+// ExportConfigKey is the config key naming the export `Run` calls, when the input doesn't name one.
+const ExportConfigKey = "extism_export"
+
+// exportHeader is the input envelope header naming the export `Run` calls.
+const exportHeader = "Export"
+
+var exports = map[string]func() int32{}
+
+// Register registers `fn` as the export called `name`, for `Run` to dispatch to.
+//
+// The standard Go compiler can only export `_start` via WASI, so a module built with it calls
+// `Register` for each of its exports from `main`, then calls `Run`.
+func Register(name string, fn func() int32) {
+	exports[name] = fn
+}
+
+// Run calls the registered export selected by the host, then exits with its return code.
+//
+// The export is named by the "Export" header of an input envelope, in which case the export sees
+// the envelope payload as its input:
+//
+//	extism-envelope/1
+//	Export: count_vowels
+//
+//	this is a test
+//
+// Otherwise, it is named by the `ExportConfigKey` config value. If neither is set and a single
+// export is registered (besides the one registered by `Describe`), that export is called.
+//
+// In native builds, Run configures the local host from the command-line flags first (see package
+// github.com/extism/go-pdk/native), and writes the output to stdout and the error to stderr.
+func Run() {
+	start()
+	exit(Pooled(dispatch))
+}
+
+func dispatch() int32 {
+	name := ""
+	if envelope, ok := InputEnvelope(); ok {
+		name = envelope.Metadata[exportHeader]
+	}
+	if name == "" {
+		name, _ = GetConfig(ExportConfigKey)
+	}
+	if name == "" {
+		name = onlyExport()
+	}
+
+	if name == "" {
+		SetErrorString("no export selected, set the " + ExportConfigKey + " config key to one of: " + exportNames())
+		return 1
+	}
+
+	fn, ok := exports[name]
+	if !ok {
+		SetErrorString("unknown export " + name + ", expected one of: " + exportNames())
+		return 1
+	}
+
+	return fn()
+}
+
+// onlyExport returns the name of the single registered export, not counting the one registered by
+// `Describe`, or "" if there are several.
+func onlyExport() string {
+	only := ""
+	for name := range exports {
+		if name == DescribeExportName {
+			continue
+		}
+		if only != "" {
+			return ""
+		}
+		only = name
+	}
+	return only
+}
+
+func exportNames() string {
+	names := make([]string, 0, len(exports))
+	for name := range exports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -1,8 +1,9 @@
 package pdk
 
 import (
+	"bytes"
 	"encoding/binary"
-	"encoding/json"
+	"strconv"
 
 	"github.com/extism/go-pdk/internal/http"
 	"github.com/extism/go-pdk/internal/memory"
@@ -49,26 +50,48 @@ func loadInput() []byte {
 	return buf
 }
 
+// input holds the last envelope returned by `InputEnvelope`, and the host input it was parsed
+// from. Modules built with TinyGo or //go:wasmexport stay loaded between calls, and the host input
+// changes with every call, so the envelope only stands for the input while the host input is the
+// same.
+var input struct {
+	raw      []byte
+	envelope *Envelope
+}
+
+// envelopePayload returns the payload of the envelope returned by `InputEnvelope`, if it was
+// parsed from the host input `raw`.
+func envelopePayload(raw []byte) ([]byte, bool) {
+	if input.envelope == nil || !bytes.Equal(raw, input.raw) {
+		return nil, false
+	}
+	return input.envelope.Payload, true
+}
+
 // Input returns a slice of bytes from the host.
 func Input() []byte {
-	return loadInput()
+	data := loadInput()
+	if payload, ok := envelopePayload(data); ok {
+		return append([]byte(nil), payload...)
+	}
+	return data
 }
 
 // JSONFrom unmarshals a `Memory` block located at `offset` from the host
 // into the provided data `v`.
 func JSONFrom(offset uint64, v any) error {
 	mem := FindMemory(offset)
-	return json.Unmarshal(mem.ReadBytes(), v)
+	return unmarshalJSON(mem.ReadBytes(), v)
 }
 
 // InputJSON returns unmartialed JSON data from the host "input".
 func InputJSON(v any) error {
-	return json.Unmarshal(Input(), v)
+	return unmarshalJSON(Input(), v)
 }
 
 // OutputJSON marshals the provided data `v` as output to the host.
 func OutputJSON(v any) error {
-	b, err := json.Marshal(v)
+	b, err := marshalJSON(v)
 	if err != nil {
 		return err
 	}
@@ -80,6 +103,61 @@ func OutputJSON(v any) error {
 	return nil
 }
 
+// Arena tracks the host memory blocks allocated through it, to release them all at once with
+// `Reset` (for reuse by the arena) or `Release`. Blocks which must outlive it, such as those passed
+// to `OutputMemory`, must be detached with `Keep`.
+type Arena = memory.Arena
+
+// NewArena returns an `Arena` whose blocks released by `Reset` are pooled for reuse by its later
+// allocations of the same length, saving host calls for short-lived data.
+//
+// The host resets its memory at the start of every call, so an Arena must not outlive the call it
+// was created in: its blocks must be freed with `Release` before the export returns.
+func NewArena() *Arena {
+	return memory.NewArena(memory.NewPool(256, 4))
+}
+
+// callPool pools the blocks of the config keys, var keys and log messages the helpers pass to the
+// host, during a call run by `Pooled`. It is nil outside of one.
+var callPool *memory.Pool
+
+// Pooled runs the export `fn`, letting the helpers such as `GetConfig`, `GetVar`, `SetVar` and
+// `Log` reuse the host blocks of the keys and messages they pass to the host, instead of
+// allocating and freeing one each time, and frees the pooled blocks once `fn` returns, before the
+// host resets its memory for the next call. `Run`, `Serve` and the `Call` of package
+// github.com/extism/go-pdk/wasi-reactor run their export this way.
+//
+// Blocks are only reused for data of the same length, such as a key read repeatedly. Outside of
+// `Pooled`, the helpers allocate and free a block for every host call.
+func Pooled(fn func() int32) int32 {
+	if callPool != nil {
+		return fn()
+	}
+	callPool = memory.NewPool(256, 4)
+	defer func() {
+		callPool.Drain()
+		callPool = nil
+	}()
+	return fn()
+}
+
+// allocateTemporary returns a block holding `s`, for a helper to pass to the host and release with
+// `freeTemporary` once the host call returns.
+func allocateTemporary(s string) Memory {
+	if callPool != nil {
+		return callPool.AllocateBytes([]byte(s))
+	}
+	return AllocateString(s)
+}
+
+func freeTemporary(mem Memory) {
+	if callPool != nil {
+		callPool.Free(mem)
+		return
+	}
+	mem.Free()
+}
+
 func Allocate(length int) Memory {
 	return memory.Allocate(length)
 }
@@ -95,7 +173,7 @@ func AllocateString(data string) Memory {
 
 // AllocateJSON allocates and saves the type `any` into Memory on the host.
 func AllocateJSON(v any) (Memory, error) {
-	b, err := json.Marshal(v)
+	b, err := marshalJSON(v)
 	if err != nil {
 		return Memory{}, err
 	}
@@ -109,7 +187,7 @@ func InputString() string {
 }
 
 // OutputMemory sends the `mem` Memory to the host output.
-// Note that the `mem` is _NOT_ freed and is your responsibility to free when finished with it.
+// Note that the host reads the output once the export returns, so `mem` must not be freed before.
 func OutputMemory(mem Memory) {
 	extismOutputSet(memory.ExtismPointer(mem.Offset()), mem.Length())
 }
@@ -144,8 +222,8 @@ func SetErrorString(err string) {
 
 // GetConfig returns the config string associated with `key` (if any).
 func GetConfig(key string) (string, bool) {
-	mem := AllocateBytes([]byte(key))
-	defer mem.Free()
+	mem := allocateTemporary(key)
+	defer freeTemporary(mem)
 
 	offset := extismConfigGet(memory.ExtismPointer(mem.Offset()))
 	clength := memory.ExtismLength(offset)
@@ -181,17 +259,20 @@ func LogMemory(level LogLevel, m Memory) {
 
 // Log logs the provided UTF-8 string `s` on the host using the provided log `level`.
 func Log(level LogLevel, s string) {
-	mem := AllocateString(s)
-	// TODO: coordinate replacement of call to free based on SDK alignment
-	// defer mem.Free()
+	if level < LogLevel(extismGetLogLevel()) {
+		return
+	}
+
+	mem := allocateTemporary(s)
+	defer freeTemporary(mem)
 
 	LogMemory(level, mem)
 }
 
 // GetVar returns the byte slice (if any) associated with `key`.
 func GetVar(key string) []byte {
-	mem := AllocateBytes([]byte(key))
-	defer mem.Free()
+	mem := allocateTemporary(key)
+	defer freeTemporary(mem)
 
 	offset := extismVarGet(memory.ExtismPointer(mem.Offset()))
 	clength := memory.ExtismLength(offset)
@@ -207,9 +288,8 @@ func GetVar(key string) []byte {
 
 // SetVar sets the host variable associated with `key` to the `value` byte slice.
 func SetVar(key string, value []byte) {
-	keyMem := AllocateBytes([]byte(key))
-	// TODO: coordinate replacement of call to free based on SDK alignment
-	// defer keyMem.Free()
+	keyMem := allocateTemporary(key)
+	defer freeTemporary(keyMem)
 
 	valMem := AllocateBytes(value)
 	// TODO: coordinate replacement of call to free based on SDK alignment
@@ -223,8 +303,8 @@ func SetVar(key string, value []byte) {
 
 // GetVarInt returns the int associated with `key` (or 0 if none).
 func GetVarInt(key string) int {
-	mem := AllocateBytes([]byte(key))
-	defer mem.Free()
+	mem := allocateTemporary(key)
+	defer freeTemporary(mem)
 
 	offset := extismVarGet(memory.ExtismPointer(mem.Offset()))
 	clength := memory.ExtismLength(offset)
@@ -240,9 +320,8 @@ func GetVarInt(key string) int {
 
 // SetVarInt sets the host variable associated with `key` to the `value` int.
 func SetVarInt(key string, value int) {
-	keyMem := AllocateBytes([]byte(key))
-	// TODO: coordinate replacement of call to free based on SDK alignment
-	// defer keyMem.Free()
+	keyMem := allocateTemporary(key)
+	defer freeTemporary(keyMem)
 
 	bytes := make([]byte, 8)
 	binary.LittleEndian.PutUint64(bytes, uint64(value))
@@ -259,9 +338,8 @@ func SetVarInt(key string, value int) {
 
 // RemoveVar removes (and frees) the host variable associated with `key`.
 func RemoveVar(key string) {
-	mem := AllocateBytes([]byte(key))
-	// TODO: coordinate replacement of call to free based on SDK alignment
-	// defer mem.Free()
+	mem := allocateTemporary(key)
+	defer freeTemporary(mem)
 	extismVarSet(memory.ExtismPointer(mem.Offset()), 0)
 }
 
@@ -272,10 +350,27 @@ type HTTPRequestMeta struct {
 	Headers map[string]string `json:"headers"`
 }
 
+// MaxResponseBytes is the default limit on the size of HTTP response bodies returned by the host,
+// applied to requests which don't set their own limit. 0 means no limit.
+var MaxResponseBytes uint64
+
+// ResponseTooLargeError is returned when an HTTP response body is larger than the configured limit.
+// The body is freed on the host without being copied into the plugin.
+type ResponseTooLargeError struct {
+	Length uint64
+	Limit  uint64
+}
+
+func (e *ResponseTooLargeError) Error() string {
+	return "HTTP response body of " + strconv.FormatUint(e.Length, 10) +
+		" bytes exceeds the limit of " + strconv.FormatUint(e.Limit, 10) + " bytes"
+}
+
 // HTTPRequest represents an HTTP request sent by the host.
 type HTTPRequest struct {
-	meta HTTPRequestMeta
-	body []byte
+	meta             HTTPRequestMeta
+	body             []byte
+	maxResponseBytes uint64
 }
 
 // HTTPResponse represents an HTTP response returned from the host.
@@ -363,6 +458,17 @@ func NewHTTPRequest(method HTTPMethod, url string) *HTTPRequest {
 	}
 }
 
+// Meta returns the URL, method and headers of the `HTTPRequest`. Its Headers map is the request's
+// own.
+func (r *HTTPRequest) Meta() HTTPRequestMeta {
+	return r.meta
+}
+
+// Body returns the body of the `HTTPRequest`, or nil if it has none.
+func (r *HTTPRequest) Body() []byte {
+	return r.body
+}
+
 // SetHeader sets an HTTP header `key` to `value`.
 func (r *HTTPRequest) SetHeader(key string, value string) *HTTPRequest {
 	if r.meta.Headers == nil {
@@ -378,9 +484,25 @@ func (r *HTTPRequest) SetBody(body []byte) *HTTPRequest {
 	return r
 }
 
+// SetMaxResponseBytes limits the size of the response body to `limit` bytes, overriding
+// `MaxResponseBytes` for this request. 0 falls back to `MaxResponseBytes`.
+func (r *HTTPRequest) SetMaxResponseBytes(limit uint64) *HTTPRequest {
+	r.maxResponseBytes = limit
+	return r
+}
+
 // Send sends the `HTTPRequest` from the host and returns the `HTTPResponse`.
+// A response body exceeding the size limit is dropped; use `Do` to detect that case.
 func (r *HTTPRequest) Send() HTTPResponse {
-	enc, _ := json.Marshal(r.meta)
+	resp, _ := r.Do()
+	return resp
+}
+
+// Do sends the `HTTPRequest` from the host and returns the `HTTPResponse`. If the response body
+// exceeds the size limit, the returned response only carries the status and headers, and the error
+// is a `*ResponseTooLargeError`.
+func (r *HTTPRequest) Do() (HTTPResponse, error) {
+	enc := http.EncodeRequest(r.meta.URL, r.meta.Method, r.meta.Headers)
 
 	req := AllocateBytes(enc)
 	defer req.Free()
@@ -391,21 +513,26 @@ func (r *HTTPRequest) Send() HTTPResponse {
 		dataOffset = memory.ExtismPointer(data.Offset())
 	}
 
-	offset := http.ExtismHTTPRequest(
-		memory.ExtismPointer(req.Offset()),
-		dataOffset,
-	)
+	sent := http.Send(memory.ExtismPointer(req.Offset()), dataOffset)
+	offset := sent.Body
 	length := memory.ExtismLengthUnsafe(offset)
-	status := uint16(http.ExtismHTTPStatusCode())
+	status := uint16(sent.Status)
 
-	headersOffs := http.ExtismHTTPHeaders()
+	headersOffs := sent.Headers
 	headers := map[string]string{}
 
 	if headersOffs != 0 {
 		length := memory.ExtismLengthUnsafe(headersOffs)
 		mem := memory.NewMemory(headersOffs, length)
 		defer mem.Free()
-		json.Unmarshal(mem.ReadBytes(), &headers)
+		if decoded, err := http.DecodeHeaders(mem.ReadBytes()); err == nil {
+			headers = decoded
+		}
+	}
+
+	if limit := ResponseLimit(r.maxResponseBytes); limit != 0 && length > limit {
+		memory.ExtismFree(offset)
+		return HTTPResponse{status: status, headers: headers}, &ResponseTooLargeError{Length: length, Limit: limit}
 	}
 
 	memory := memory.NewMemory(offset, length)
@@ -414,7 +541,16 @@ func (r *HTTPRequest) Send() HTTPResponse {
 		memory,
 		status,
 		headers,
+	}, nil
+}
+
+// ResponseLimit returns the HTTP response size limit that applies given a per-request `limit`,
+// which takes precedence over `MaxResponseBytes` when non-zero. 0 means no limit.
+func ResponseLimit(limit uint64) uint64 {
+	if limit != 0 {
+		return limit
 	}
+	return MaxResponseBytes
 }
 
 // FindMemory finds the host memory block at the given `offset`.
@@ -438,11 +574,17 @@ func ParamString(offset uint64) string {
 }
 
 // ParamU32 returns a uint32 from Extism host memory given an offset.
+// It panics if the memory block holds fewer than 4 bytes.
+//
+// Deprecated: use `Param[uint32]`, which returns an error instead.
 func ParamU32(offset uint64) uint32 {
 	return binary.LittleEndian.Uint32(ParamBytes(offset))
 }
 
 // ParamU64 returns a uint64 from Extism host memory given an offset.
+// It panics if the memory block holds fewer than 8 bytes.
+//
+// Deprecated: use `Param[uint64]`, which returns an error instead.
 func ParamU64(offset uint64) uint64 {
 	return binary.LittleEndian.Uint64(ParamBytes(offset))
 }
@@ -0,0 +1,61 @@
+package pdk
+
+// Call holds the state of a plugin call, shared by a `Handler` and the `Middleware` wrapping it.
+type Call struct {
+	// Input is the input data from the host.
+	Input []byte
+	// Output is sent to the host output once the handler returns, unless Err is set.
+	Output []byte
+	// Err is sent to the host as the call error once the handler returns.
+	Err error
+	// Code is the return code of the export. It becomes 1 if Err is set and Code is 0.
+	Code int32
+}
+
+// Handler handles a plugin call, reading `c.Input` and setting `c.Output`, or `c.Err` on failure.
+type Handler func(c *Call)
+
+// Middleware wraps a `Handler` with cross-cutting behavior, running code before and after it, or
+// instead of it.
+type Middleware func(next Handler) Handler
+
+// Chain returns a `Middleware` applying `middlewares` in order, the first one being the outermost.
+func Chain(middlewares ...Middleware) Middleware {
+	return func(next Handler) Handler {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			next = middlewares[i](next)
+		}
+		return next
+	}
+}
+
+// Serve runs `h` with the host input, sends its output or error to the host, and returns the code
+// the export should return:
+//
+//	var chain = pdk.Chain(pdk.Recover(), pdk.Timing(pdk.LogDebug))
+//
+//	//go:wasmexport greet
+//	func greet() int32 {
+//		return pdk.Serve(chain(func(c *pdk.Call) {
+//			c.Output = []byte("Hello, " + string(c.Input) + "!")
+//		}))
+//	}
+func Serve(h Handler) int32 {
+	return Pooled(func() int32 {
+		c := &Call{Input: Input()}
+		h(c)
+
+		if c.Err != nil {
+			SetError(c.Err)
+			if c.Code == 0 {
+				c.Code = 1
+			}
+			return c.Code
+		}
+
+		if c.Output != nil {
+			Output(c.Output)
+		}
+		return c.Code
+	})
+}
@@ -0,0 +1,38 @@
+package pdk
+
+import (
+	"github.com/extism/go-pdk/internal/memory"
+)
+
+// CallHost calls the host function `fn` with `in` and returns its result decoded as `Out`.
+//
+// `fn` is a function imported from the host, taking and returning the offset of a memory block:
+//
+//	//go:wasmimport extism:host/user a_python_func
+//	func aPythonFunc(uint64) uint64
+//
+// The argument is encoded into a newly allocated memory block, which is freed once `fn` returns,
+// and the memory block returned by `fn` is decoded and freed. Strings and byte slices are passed
+// as-is, booleans as a single byte, fixed-size numbers in little-endian and any other type as JSON.
+func CallHost[In, Out any](fn func(uint64) uint64, in In) (Out, error) {
+	var out Out
+
+	data, err := encodeValue(in)
+	if err != nil {
+		return out, err
+	}
+
+	arg := AllocateBytes(data)
+	defer arg.Free()
+
+	offset := fn(arg.Offset())
+	if offset == 0 {
+		return out, decodeValue(nil, &out)
+	}
+
+	result := FindMemory(offset)
+	data = result.ReadBytes()
+	memory.ExtismFree(memory.ExtismPointer(offset))
+
+	return out, decodeValue(data, &out)
+}
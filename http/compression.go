@@ -0,0 +1,40 @@
+package http
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+)
+
+// acceptEncoding is the Accept-Encoding header sent when HTTPTransport.EnableCompression is set.
+const acceptEncoding = "gzip, deflate"
+
+// isSupportedEncoding reports whether the response Content-Encoding `encoding` can be
+// decompressed by the transport.
+func isSupportedEncoding(encoding string) bool {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "gzip", "x-gzip", "deflate":
+		return true
+	default:
+		return false
+	}
+}
+
+// newDecompressor returns a reader decompressing `data` according to the Content-Encoding
+// `encoding`. The "deflate" encoding is meant to be zlib-wrapped, but some servers send a raw
+// deflate stream, so that is accepted as well.
+func newDecompressor(encoding string, data []byte) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "deflate":
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err == zlib.ErrHeader {
+			return flate.NewReader(bytes.NewReader(data)), nil
+		}
+		return r, err
+	default:
+		return gzip.NewReader(bytes.NewReader(data))
+	}
+}
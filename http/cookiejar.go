@@ -0,0 +1,256 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	pdk "github.com/extism/go-pdk"
+)
+
+// CookieJar implements go's http.CookieJar interface on top of an Extism var, so cookies set by
+// responses persist across calls to the same plugin instance. It is meant to be used as the Jar
+// of an http.Client whose Transport is an HTTPTransport.
+//
+// Domain matching follows RFC 6265 but, unlike net/http/cookiejar, does not consult the public
+// suffix list.
+type CookieJar struct {
+	key string
+}
+
+// NewCookieJar returns a `CookieJar` storing its cookies in the host var named `key`.
+func NewCookieJar(key string) *CookieJar {
+	return &CookieJar{key: key}
+}
+
+// storedCookie is the representation of a cookie saved in the jar's var.
+type storedCookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain"`
+	Path     string `json:"path"`
+	HostOnly bool   `json:"host_only,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	// Expires is the expiry time in Unix seconds, or 0 for a session cookie.
+	Expires int64 `json:"expires,omitempty"`
+}
+
+func (c *storedCookie) expired(now time.Time) bool {
+	return c.Expires != 0 && c.Expires <= now.Unix()
+}
+
+func (c *storedCookie) matches(host, urlPath string, secure bool) bool {
+	if c.Secure && !secure {
+		return false
+	}
+	if c.HostOnly {
+		if host != c.Domain {
+			return false
+		}
+	} else if !domainMatch(host, c.Domain) {
+		return false
+	}
+	return pathMatch(urlPath, c.Path)
+}
+
+// SetCookies stores the `cookies` received in a response for `u`.
+func (j *CookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	if len(cookies) == 0 {
+		return
+	}
+
+	host := strings.ToLower(u.Hostname())
+	now := time.Now()
+	stored := j.load()
+
+	for _, cookie := range cookies {
+		entry := storedCookie{
+			Name:   cookie.Name,
+			Value:  cookie.Value,
+			Path:   cookie.Path,
+			Secure: cookie.Secure,
+		}
+
+		domain := strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
+		if domain == "" {
+			entry.Domain = host
+			entry.HostOnly = true
+		} else if domainMatch(host, domain) {
+			entry.Domain = domain
+		} else {
+			continue
+		}
+
+		if entry.Path == "" || entry.Path[0] != '/' {
+			entry.Path = defaultPath(u.Path)
+		}
+
+		switch {
+		case cookie.MaxAge < 0:
+			entry.Expires = now.Unix()
+		case cookie.MaxAge > 0:
+			entry.Expires = now.Add(time.Duration(cookie.MaxAge) * time.Second).Unix()
+		case !cookie.Expires.IsZero():
+			entry.Expires = cookie.Expires.Unix()
+		}
+
+		if entry.expired(now) {
+			stored = removeCookie(stored, entry)
+			continue
+		}
+
+		replaced := false
+		for i := range stored {
+			if stored[i].Name == entry.Name && stored[i].Domain == entry.Domain && stored[i].Path == entry.Path {
+				stored[i] = entry
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			stored = append(stored, entry)
+		}
+	}
+
+	j.save(stored, now)
+}
+
+// Cookies returns the cookies to send in a request for `u`.
+func (j *CookieJar) Cookies(u *url.URL) []*http.Cookie {
+	host := strings.ToLower(u.Hostname())
+	urlPath := u.Path
+	if urlPath == "" {
+		urlPath = "/"
+	}
+	secure := u.Scheme == "https"
+	now := time.Now()
+
+	var matched []storedCookie
+	for _, c := range j.load() {
+		if !c.expired(now) && c.matches(host, urlPath, secure) {
+			matched = append(matched, c)
+		}
+	}
+
+	// RFC 6265 section 5.4: cookies with longer paths are listed first
+	sort.SliceStable(matched, func(a, b int) bool {
+		return len(matched[a].Path) > len(matched[b].Path)
+	})
+
+	result := make([]*http.Cookie, 0, len(matched))
+	for _, c := range matched {
+		result = append(result, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+
+	return result
+}
+
+func (j *CookieJar) load() []storedCookie {
+	data := pdk.GetVar(j.key)
+	if data == nil {
+		return nil
+	}
+
+	var stored []storedCookie
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil
+	}
+
+	return stored
+}
+
+func (j *CookieJar) save(stored []storedCookie, now time.Time) {
+	live := stored[:0]
+	for _, c := range stored {
+		if !c.expired(now) {
+			live = append(live, c)
+		}
+	}
+
+	if len(live) == 0 {
+		pdk.RemoveVar(j.key)
+		return
+	}
+
+	data, err := json.Marshal(live)
+	if err != nil {
+		return
+	}
+
+	pdk.SetVar(j.key, data)
+}
+
+func removeCookie(stored []storedCookie, entry storedCookie) []storedCookie {
+	result := stored[:0]
+	for _, c := range stored {
+		if c.Name == entry.Name && c.Domain == entry.Domain && c.Path == entry.Path {
+			continue
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// domainMatch reports whether `host` domain-matches `domain` (RFC 6265 section 5.1.3).
+func domainMatch(host, domain string) bool {
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// pathMatch reports whether `urlPath` path-matches `cookiePath` (RFC 6265 section 5.1.4).
+func pathMatch(urlPath, cookiePath string) bool {
+	if urlPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(urlPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || urlPath[len(cookiePath)] == '/'
+}
+
+// defaultPath returns the default cookie path for `urlPath` (RFC 6265 section 5.1.4).
+func defaultPath(urlPath string) string {
+	if urlPath == "" || urlPath[0] != '/' {
+		return "/"
+	}
+	dir := path.Dir(urlPath)
+	if dir == "." {
+		return "/"
+	}
+	return dir
+}
+
+// splitSetCookie splits a Set-Cookie header value the host may have joined with commas back into
+// individual cookies. Commas inside attribute values (most notably in Expires dates) are kept,
+// since a new cookie only starts where the text after a comma looks like "name=".
+func splitSetCookie(value string) []string {
+	var result []string
+	start := 0
+	for i := 0; i < len(value); i++ {
+		if value[i] != ',' || !startsCookie(value[i+1:]) {
+			continue
+		}
+		if part := strings.TrimSpace(value[start:i]); part != "" {
+			result = append(result, part)
+		}
+		start = i + 1
+	}
+	if part := strings.TrimSpace(value[start:]); part != "" {
+		result = append(result, part)
+	}
+	return result
+}
+
+// startsCookie reports whether `s` begins with a "name=" pair rather than the continuation of an
+// attribute value.
+func startsCookie(s string) bool {
+	s = strings.TrimLeft(s, " \t")
+	eq := strings.IndexByte(s, '=')
+	if eq <= 0 {
+		return false
+	}
+	return !strings.ContainsAny(s[:eq], " \t;,")
+}
@@ -0,0 +1,182 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	pdk "github.com/extism/go-pdk"
+)
+
+// RequestEnvelope is the JSON document a host sends as plugin input to have an HTTP request
+// served by ServeHTTP:
+//
+//	{"method": "POST", "url": "/items?id=1", "headers": {"Content-Type": ["text/plain"]}, "body": "aGVsbG8="}
+//
+// The body is base64 encoded, and the URL may be absolute or relative to the plugin.
+type RequestEnvelope struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+	RemoteAddr string      `json:"remote_addr,omitempty"`
+}
+
+// ResponseEnvelope is the JSON document ServeHTTP sends as plugin output once the handler has
+// run:
+//
+//	{"status": 200, "headers": {"Content-Type": ["text/plain; charset=utf-8"]}, "body": "aGVsbG8="}
+//
+// The body is base64 encoded.
+type ResponseEnvelope struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    []byte      `json:"body,omitempty"`
+}
+
+// ServeHTTP serves the `RequestEnvelope` read from the plugin input with `handler`, and outputs
+// the resulting `ResponseEnvelope`. It returns the code the calling export should return: 0 on
+// success, or 1 (with the host error set) if the input is not a valid request envelope or the
+// handler panics. Like net/http, a panic is recovered and logged with its stack, unless it is
+// http.ErrAbortHandler, and the response is dropped.
+//
+// This allows routers such as http.ServeMux to be used inside a plugin:
+//
+//	//go:wasmexport handle
+//	func handle() int32 {
+//		return pdkhttp.ServeHTTP(mux)
+//	}
+func ServeHTTP(handler http.Handler) int32 {
+	return pdk.Pooled(func() int32 {
+		return serveRequest(handler)
+	})
+}
+
+// serveRequest is ServeHTTP, within the pool of the call.
+func serveRequest(handler http.Handler) int32 {
+	var envelope RequestEnvelope
+	if err := json.Unmarshal(pdk.Input(), &envelope); err != nil {
+		pdk.SetError(fmt.Errorf("failed to decode request envelope: %w", err))
+		return 1
+	}
+
+	req, err := envelope.Request()
+	if err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+
+	w := newResponseWriter()
+	if err := serve(handler, w, req); err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+
+	out, err := json.Marshal(w.envelope())
+	if err != nil {
+		pdk.SetError(fmt.Errorf("failed to encode response envelope: %w", err))
+		return 1
+	}
+
+	pdk.Output(out)
+	return 0
+}
+
+// serve runs `handler`, turning a panic into an error.
+func serve(handler http.Handler, w http.ResponseWriter, req *http.Request) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("http: panic serving %s: %v", req.URL, r)
+			if r != http.ErrAbortHandler {
+				pdk.Log(pdk.LogError, err.Error()+"\n"+string(debug.Stack()))
+			}
+		}
+	}()
+
+	handler.ServeHTTP(w, req)
+	return nil
+}
+
+// Request converts the envelope into a server-side http.Request, as a handler would receive it
+// from net/http.
+func (e *RequestEnvelope) Request() (*http.Request, error) {
+	method := e.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequest(method, e.URL, bytes.NewReader(e.Body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid request envelope: %w", err)
+	}
+
+	// hosts may send header names in any case, while handlers look them up in canonical form
+	for name, values := range e.Headers {
+		name = http.CanonicalHeaderKey(name)
+		req.Header[name] = append(req.Header[name], values...)
+	}
+	if host := req.Header.Get("Host"); host != "" {
+		req.Host = host
+		req.Header.Del("Host")
+	}
+	req.ContentLength = int64(len(e.Body))
+	req.RequestURI = e.URL
+	req.RemoteAddr = e.RemoteAddr
+
+	return req, nil
+}
+
+// responseWriter is the http.ResponseWriter handed to the handler, buffering the response until
+// it is written to the plugin output.
+type responseWriter struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newResponseWriter() *responseWriter {
+	return &responseWriter{header: http.Header{}}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.status = status
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.header.Get("Content-Type") == "" && w.header.Get("Transfer-Encoding") == "" {
+			w.header.Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.body.Write(p)
+}
+
+func (w *responseWriter) envelope() ResponseEnvelope {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	headers := w.header.Clone()
+	if headers.Get("Content-Length") == "" && w.body.Len() > 0 {
+		headers.Set("Content-Length", strconv.Itoa(w.body.Len()))
+	}
+
+	return ResponseEnvelope{
+		Status:  w.status,
+		Headers: headers,
+		Body:    w.body.Bytes(),
+	}
+}
@@ -0,0 +1,98 @@
+//go:build !wasm
+
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/extism/go-pdk/native"
+)
+
+func serveEnvelope(t *testing.T, handler http.Handler, input string) (ResponseEnvelope, string, int32) {
+	t.Helper()
+
+	output, errMsg, code := native.Call([]byte(input), func() int32 {
+		return ServeHTTP(handler)
+	})
+	var resp ResponseEnvelope
+	if code == 0 {
+		if err := json.Unmarshal(output, &resp); err != nil {
+			t.Fatalf("invalid response envelope %q: %v", output, err)
+		}
+	}
+	return resp, errMsg, code
+}
+
+func TestServeHTTP(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, r.URL.Query().Get("name")+":"+string(body))
+	})
+
+	resp, errMsg, code := serveEnvelope(t, handler, `{"method":"PUT","url":"/items?name=a","body":"aGVsbG8="}`)
+	if code != 0 {
+		t.Fatalf("ServeHTTP returned %d: %s", code, errMsg)
+	}
+	if resp.Status != http.StatusCreated {
+		t.Errorf("status = %d, want %d", resp.Status, http.StatusCreated)
+	}
+	if got := resp.Headers.Get("X-Method"); got != "PUT" {
+		t.Errorf("X-Method = %q, want PUT", got)
+	}
+	if got := resp.Headers.Get("Content-Length"); got != "7" {
+		t.Errorf("Content-Length = %q, want 7", got)
+	}
+	if string(resp.Body) != "a:hello" {
+		t.Errorf("body = %q, want a:hello", resp.Body)
+	}
+}
+
+func TestServeHTTPHeaderCase(t *testing.T) {
+	var contentType, host string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		host = r.Host
+	})
+
+	_, errMsg, code := serveEnvelope(t, handler,
+		`{"method":"POST","url":"/","headers":{"content-type":["text/plain"],"host":["example.com"]}}`)
+	if code != 0 {
+		t.Fatalf("ServeHTTP returned %d: %s", code, errMsg)
+	}
+	if contentType != "text/plain" {
+		t.Errorf("Content-Type = %q, want text/plain", contentType)
+	}
+	if host != "example.com" {
+		t.Errorf("Host = %q, want example.com", host)
+	}
+}
+
+func TestServeHTTPPanic(t *testing.T) {
+	native.Configure(native.Options{Stderr: io.Discard})
+	defer native.Configure(native.Options{})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "partial")
+		panic("boom")
+	})
+
+	_, errMsg, code := serveEnvelope(t, handler, `{"url":"/panic"}`)
+	if code != 1 {
+		t.Fatalf("ServeHTTP returned %d, want 1", code)
+	}
+	if !strings.Contains(errMsg, "panic serving /panic: boom") {
+		t.Errorf("error = %q, want the panic message", errMsg)
+	}
+
+	// the instance is still usable
+	resp, errMsg, code := serveEnvelope(t, http.NotFoundHandler(), `{"url":"/"}`)
+	if code != 0 || resp.Status != http.StatusNotFound {
+		t.Errorf("ServeHTTP after a panic returned %d (%s), status %d", code, errMsg, resp.Status)
+	}
+}
@@ -2,11 +2,13 @@ package http
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	pdk "github.com/extism/go-pdk"
 	extismhttp "github.com/extism/go-pdk/internal/http"
@@ -15,15 +17,74 @@ import (
 
 // HTTPTransport implement go's http.RoundTripper interface, enabling usage of standard go
 // http.Client within a plugin
+//
+// Redirects are followed by the host, according to its own configuration, before the response
+// reaches the plugin. An http.Client using this transport therefore only sees the final response,
+// and its CheckRedirect policy (as well as its Jar, for intermediate responses) is only consulted
+// for 3xx responses the host hands back unfollowed. Plugins that need to observe every hop should
+// use a host configured not to follow redirects; the Location header of such responses is passed
+// through unchanged so http.Client can follow them itself.
 type HTTPTransport struct {
+	// EnableCompression, if true, makes the transport request gzip and deflate compressed
+	// responses (when the request has no Accept-Encoding header of its own) and transparently
+	// decompress them, like net/http's Transport does for gzip. Decompressed responses have
+	// Uncompressed set, and their Content-Encoding and Content-Length headers removed.
+	EnableCompression bool
+
+	// MaxResponseBytes limits the size of response bodies returned by the host. Requests can
+	// override it with WithMaxResponseBytes; 0 falls back to pdk.MaxResponseBytes. Bodies over
+	// the limit make RoundTrip fail with a *pdk.ResponseTooLargeError.
+	MaxResponseBytes uint64
 }
 
+type maxResponseBytesKey struct{}
+
+// WithMaxResponseBytes returns a copy of `ctx` limiting the response body size of requests made
+// with it to `limit` bytes, overriding HTTPTransport.MaxResponseBytes.
+func WithMaxResponseBytes(ctx context.Context, limit uint64) context.Context {
+	return context.WithValue(ctx, maxResponseBytesKey{}, limit)
+}
+
+func (t *HTTPTransport) responseLimit(ctx context.Context) uint64 {
+	if limit, ok := ctx.Value(maxResponseBytesKey{}).(uint64); ok && limit != 0 {
+		return limit
+	}
+	return pdk.ResponseLimit(t.MaxResponseBytes)
+}
+
+// contextErr returns the error of `ctx` if it is done or if its deadline has passed. Host calls
+// block the plugin, so the deadline is checked directly rather than waiting on ctx.Done().
+func contextErr(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok && !time.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// RoundTrip sends `req` through the host. It always closes the request body, and returns
+// responses with a non-nil Body (http.NoBody when empty) whose memory has been released on the
+// host. Requests whose context is done, or past its deadline, fail with the context error.
 func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	ctx := req.Context()
+	if err := contextErr(ctx); err != nil {
+		return nil, err
+	}
 
 	convertRequestHeaders := func() map[string]string {
 		result := map[string]string{}
 
 		for name, values := range req.Header {
+			if http.CanonicalHeaderKey(name) == "Cookie" {
+				result[name] = strings.Join(values, "; ")
+				continue
+			}
 			result[name] = strings.Join(values, ",")
 
 		}
@@ -31,22 +92,23 @@ func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		return result
 	}
 
-	meta := pdk.HTTPRequestMeta{
-		URL:     req.URL.String(),
-		Headers: convertRequestHeaders(),
-		Method:  req.Method,
-	}
+	headers := convertRequestHeaders()
 
-	metaData, err := json.Marshal(meta)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode request headers: %q", err)
+	requestedCompression := false
+	if t.EnableCompression &&
+		req.Header.Get("Accept-Encoding") == "" &&
+		req.Header.Get("Range") == "" &&
+		req.Method != "HEAD" {
+		requestedCompression = true
+		headers["Accept-Encoding"] = acceptEncoding
 	}
 
+	metaData := extismhttp.EncodeRequest(req.URL.String(), req.Method, headers)
 	metaMemory := pdk.AllocateBytes(metaData)
 	defer metaMemory.Free()
 
 	var bodyMemoryOffset memory.ExtismPointer
-	if req.Body != nil {
+	if req.Body != nil && req.Body != http.NoBody {
 		bodyData, err := io.ReadAll(req.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read body bytes: %q", err)
@@ -58,26 +120,56 @@ func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		bodyMemoryOffset = memory.ExtismPointer(bodyMemory.Offset())
 	}
 
-	respPointer := extismhttp.ExtismHTTPRequest(
-		memory.ExtismPointer(metaMemory.Offset()),
-		bodyMemoryOffset,
-	)
+	sent := extismhttp.Send(memory.ExtismPointer(metaMemory.Offset()), bodyMemoryOffset)
+	respPointer := sent.Body
 	respLength := memory.ExtismLengthUnsafe(respPointer)
-	respStatus := extismhttp.ExtismHTTPStatusCode()
+	respStatus := sent.Status
+
+	var headersData []byte
+	if sent.Headers != 0 {
+		headersMemory := memory.NewMemory(sent.Headers, memory.ExtismLengthUnsafe(sent.Headers))
+		headersData = headersMemory.ReadBytes()
+		headersMemory.Free()
+	}
+
+	if err := contextErr(ctx); err != nil {
+		if respPointer != 0 {
+			memory.ExtismFree(respPointer)
+		}
+		return nil, err
+	}
+
+	if limit := t.responseLimit(ctx); limit != 0 && respLength > limit {
+		memory.ExtismFree(respPointer)
+		return nil, &pdk.ResponseTooLargeError{Length: respLength, Limit: limit}
+	}
+
+	var respBuf []byte
+	if respPointer != 0 {
+		respMemory := memory.NewMemory(respPointer, respLength)
+		if req.Method != "HEAD" {
+			respBuf = respMemory.ReadBytes()
+		}
+		respMemory.Free()
+	}
 
-	headersPointer := extismhttp.ExtismHTTPHeaders()
 	respHeaders := map[string]string{}
 
-	if headersPointer != 0 {
-		headersLength := memory.ExtismLengthUnsafe(headersPointer)
-		headersMemory := memory.NewMemory(headersPointer, headersLength)
-		defer headersMemory.Free()
-		json.Unmarshal(headersMemory.ReadBytes(), &respHeaders)
+	if headersData != nil {
+		if decoded, err := extismhttp.DecodeHeaders(headersData); err == nil {
+			respHeaders = decoded
+		}
 	}
 
 	convertResponseHeaders := func() http.Header {
 		result := http.Header{}
 		for key, value := range respHeaders {
+			if http.CanonicalHeaderKey(key) == "Set-Cookie" {
+				for _, cookie := range splitSetCookie(value) {
+					result.Add(key, cookie)
+				}
+				continue
+			}
 			result.Add(key, value)
 		}
 
@@ -85,26 +177,59 @@ func (t *HTTPTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	}
 
 	resp := &http.Response{
-		Status:        http.StatusText(int(respStatus)),
+		Status:        statusLine(int(respStatus)),
 		StatusCode:    int(respStatus),
 		Proto:         "HTTP/1.1",
 		ProtoMajor:    1,
 		ProtoMinor:    1,
 		Header:        convertResponseHeaders(),
-		Body:          nil,
-		ContentLength: -1,
+		Body:          http.NoBody,
+		ContentLength: int64(len(respBuf)),
 		Request:       req,
 	}
 
-	hasBody := req.Method != "HEAD" && respLength > 0
-	if hasBody {
-		respMemory := memory.NewMemory(respPointer, respLength)
-		respBuf := make([]byte, respMemory.Length())
-		respMemory.Load(respBuf)
+	if req.Method == "HEAD" {
+		resp.ContentLength = contentLength(resp.Header)
+	}
 
+	if len(respBuf) > 0 {
 		resp.Body = io.NopCloser(bytes.NewReader(respBuf))
-		resp.ContentLength = int64(respLength)
+
+		if requestedCompression {
+			encoding := resp.Header.Get("Content-Encoding")
+			if isSupportedEncoding(encoding) {
+				body, err := newDecompressor(encoding, respBuf)
+				if err != nil {
+					return nil, fmt.Errorf("failed to decode %s response body: %q", encoding, err)
+				}
+
+				resp.Body = body
+				resp.Header.Del("Content-Encoding")
+				resp.Header.Del("Content-Length")
+				resp.ContentLength = -1
+				resp.Uncompressed = true
+			}
+		}
 	}
 
 	return resp, nil
 }
+
+// statusLine returns the status of a response, e.g. "200 OK", like net/http's Transport does.
+func statusLine(code int) string {
+	status := strconv.Itoa(code)
+	if text := http.StatusText(code); text != "" {
+		status += " " + text
+	}
+	return status
+}
+
+// contentLength returns the length announced by the Content-Length header, or -1 if it is
+// missing or invalid.
+func contentLength(header http.Header) int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(header.Get("Content-Length")), 10, 64)
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
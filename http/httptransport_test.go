@@ -0,0 +1,254 @@
+//go:build !wasm
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pdk "github.com/extism/go-pdk"
+)
+
+// The transport sends its requests through the local host of native builds, to a test server.
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", "42")
+			return
+		}
+		io.WriteString(w, "hello")
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(w, r.Body)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/no-content", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/custom-status", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(599)
+	})
+	mux.HandleFunc("/cookies", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "a", Value: "1", Expires: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)})
+		http.SetCookie(w, &http.Cookie{Name: "b", Value: "2"})
+	})
+	mux.HandleFunc("/gzip", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept-Encoding") == "" {
+			io.WriteString(w, "plain")
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		io.WriteString(zw, "compressed")
+		zw.Close()
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func roundTrip(t *testing.T, transport *HTTPTransport, req *http.Request) *http.Response {
+	t.Helper()
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", req.Method, req.URL.Path, err)
+	}
+	if resp.Body == nil {
+		t.Fatalf("%s %s: nil response body", req.Method, req.URL.Path)
+	}
+	if resp.Request != req {
+		t.Errorf("%s %s: response Request is not the request sent", req.Method, req.URL.Path)
+	}
+	if resp.ProtoMajor != 1 || resp.ProtoMinor != 1 || resp.Proto != "HTTP/1.1" {
+		t.Errorf("%s %s: protocol %s (%d.%d)", req.Method, req.URL.Path, resp.Proto, resp.ProtoMajor, resp.ProtoMinor)
+	}
+	return resp
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading the body: %v", err)
+	}
+	if err := resp.Body.Close(); err != nil {
+		t.Errorf("closing the body: %v", err)
+	}
+	return string(body)
+}
+
+func TestRoundTrip(t *testing.T) {
+	server := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/hello", nil)
+	resp := roundTrip(t, &HTTPTransport{}, req)
+	if resp.StatusCode != http.StatusOK || resp.Status != "200 OK" {
+		t.Errorf("status %d %q, want 200 \"200 OK\"", resp.StatusCode, resp.Status)
+	}
+	if got := resp.Header.Get("X-Method"); got != "GET" {
+		t.Errorf("X-Method header = %q, want GET", got)
+	}
+	if resp.ContentLength != 5 {
+		t.Errorf("ContentLength = %d, want 5", resp.ContentLength)
+	}
+	if body := readBody(t, resp); body != "hello" {
+		t.Errorf("body = %q, want hello", body)
+	}
+}
+
+// closeRecorder records whether the request body was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestRoundTripRequestBody(t *testing.T) {
+	server := newTestServer(t)
+
+	body := &closeRecorder{Reader: strings.NewReader("ping")}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/echo", body)
+	resp := roundTrip(t, &HTTPTransport{}, req)
+	if got := readBody(t, resp); got != "ping" {
+		t.Errorf("body = %q, want ping", got)
+	}
+	if !body.closed {
+		t.Error("request body not closed")
+	}
+}
+
+func TestRoundTripEmptyBody(t *testing.T) {
+	server := newTestServer(t)
+
+	for _, path := range []string{"/empty", "/no-content"} {
+		req, _ := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		resp := roundTrip(t, &HTTPTransport{}, req)
+		if resp.ContentLength != 0 {
+			t.Errorf("%s: ContentLength = %d, want 0", path, resp.ContentLength)
+		}
+		if body := readBody(t, resp); body != "" {
+			t.Errorf("%s: body = %q, want none", path, body)
+		}
+	}
+}
+
+func TestRoundTripHead(t *testing.T) {
+	server := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodHead, server.URL+"/hello", nil)
+	resp := roundTrip(t, &HTTPTransport{}, req)
+	if got := resp.Header.Get("X-Method"); got != "HEAD" {
+		t.Errorf("X-Method header = %q, want HEAD", got)
+	}
+	if resp.ContentLength != 42 {
+		t.Errorf("ContentLength = %d, want 42 from the header", resp.ContentLength)
+	}
+	if body := readBody(t, resp); body != "" {
+		t.Errorf("body = %q, want none", body)
+	}
+}
+
+func TestRoundTripStatusLine(t *testing.T) {
+	server := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/no-content", nil)
+	if resp := roundTrip(t, &HTTPTransport{}, req); resp.Status != "204 No Content" {
+		t.Errorf("Status = %q, want \"204 No Content\"", resp.Status)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, server.URL+"/custom-status", nil)
+	if resp := roundTrip(t, &HTTPTransport{}, req); resp.StatusCode != 599 || resp.Status != "599" {
+		t.Errorf("status %d %q, want 599 \"599\"", resp.StatusCode, resp.Status)
+	}
+}
+
+func TestRoundTripContext(t *testing.T) {
+	server := newTestServer(t)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, test := range []struct {
+		ctx  context.Context
+		want error
+	}{
+		{canceled, context.Canceled},
+		{expired, context.DeadlineExceeded},
+	} {
+		req, _ := http.NewRequestWithContext(test.ctx, http.MethodGet, server.URL+"/hello", nil)
+		resp, err := (&HTTPTransport{}).RoundTrip(req)
+		if !errors.Is(err, test.want) || resp != nil {
+			t.Errorf("RoundTrip = %v, %v, want %v", resp, err, test.want)
+		}
+	}
+}
+
+func TestRoundTripSetCookie(t *testing.T) {
+	server := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/cookies", nil)
+	resp := roundTrip(t, &HTTPTransport{}, req)
+	cookies := resp.Cookies()
+	if len(cookies) != 2 || cookies[0].Name != "a" || cookies[1].Name != "b" {
+		t.Errorf("cookies = %v, want a and b", cookies)
+	}
+}
+
+func TestRoundTripCompression(t *testing.T) {
+	server := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/gzip", nil)
+	resp := roundTrip(t, &HTTPTransport{EnableCompression: true}, req)
+	if !resp.Uncompressed || resp.ContentLength != -1 || resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("Uncompressed = %v, ContentLength = %d, Content-Encoding = %q", resp.Uncompressed, resp.ContentLength, resp.Header.Get("Content-Encoding"))
+	}
+	if body := readBody(t, resp); body != "compressed" {
+		t.Errorf("body = %q, want compressed", body)
+	}
+}
+
+func TestRoundTripMaxResponseBytes(t *testing.T) {
+	server := newTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/hello", nil)
+	_, err := (&HTTPTransport{MaxResponseBytes: 4}).RoundTrip(req)
+	var tooLarge *pdk.ResponseTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.Length != 5 {
+		t.Errorf("RoundTrip error = %v, want a ResponseTooLargeError for 5 bytes", err)
+	}
+}
+
+func TestClient(t *testing.T) {
+	server := newTestServer(t)
+
+	client := &http.Client{Transport: &HTTPTransport{}}
+	resp, err := client.Post(server.URL+"/echo", "text/plain", bytes.NewReader([]byte("via client")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := readBody(t, resp); body != "via client" {
+		t.Errorf("body = %q, want \"via client\"", body)
+	}
+}
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/extism/go-pdk/aws"
+	"github.com/extism/go-pdk/internal/sigv4"
+)
+
+// SigV4 implements go's http.RoundTripper interface, signing requests with AWS Signature Version 4
+// before sending them through another RoundTripper, so that an http.Client can call S3-compatible
+// and AWS APIs:
+//
+//	client := &http.Client{Transport: &pdkhttp.SigV4{Region: "us-east-1", Service: "s3"}}
+//
+// Requests are signed like aws.Sign does, and are not modified: the signed copy
+// sent has the Authorization and X-Amz-* headers set.
+type SigV4 struct {
+	// Region is the AWS region of the service, such as "us-east-1".
+	Region string
+	// Service is the signing name of the service, such as "s3" or "dynamodb".
+	Service string
+
+	// Credentials sign the requests. If its AccessKeyID is empty, the credentials are read from the
+	// plugin config for each request, with aws.CredentialsFromConfig.
+	Credentials aws.Credentials
+
+	// Transport sends the signed requests. If nil, an HTTPTransport is used.
+	Transport http.RoundTripper
+}
+
+// RoundTrip signs `req` and sends it. It always closes the request body, which it reads to hash
+// it.
+func (s *SigV4) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read body bytes: %q", err)
+		}
+	}
+
+	creds := s.Credentials
+	if creds.AccessKeyID == "" {
+		var err error
+		if creds, err = aws.CredentialsFromConfig(); err != nil {
+			return nil, err
+		}
+	}
+
+	signed := req.Clone(req.Context())
+	if body != nil {
+		signed.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	headers := sigv4.Sign(&sigv4.Request{
+		Method: req.Method,
+		URL:    req.URL,
+		Header: req.Header,
+		Body:   body,
+	}, creds, s.Region, s.Service, time.Now())
+	for name, value := range headers {
+		signed.Header.Set(name, value)
+	}
+
+	transport := s.Transport
+	if transport == nil {
+		transport = &HTTPTransport{}
+	}
+	return transport.RoundTrip(signed)
+}
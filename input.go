@@ -0,0 +1,172 @@
+package pdk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/extism/go-pdk/internal/memory"
+)
+
+// InputReader returns a reader streaming the input from the host, which unlike `Input` doesn't
+// load it whole into Go memory (unless the input is an envelope returned by `InputEnvelope`, whose
+// payload is then read).
+func InputReader() io.Reader {
+	if input.envelope != nil {
+		data := loadInput()
+		if payload, ok := envelopePayload(data); ok {
+			return bytes.NewReader(payload)
+		}
+		return bytes.NewReader(data)
+	}
+	return &inputReader{length: int(extismInputLength())}
+}
+
+type inputReader struct {
+	offset, length int
+}
+
+func (r *inputReader) Read(p []byte) (int, error) {
+	if r.offset >= r.length {
+		return 0, io.EOF
+	}
+	if rest := r.length - r.offset; len(p) > rest {
+		p = p[:rest]
+	}
+
+	n := 0
+	for ; n+8 <= len(p); n += 8 {
+		binary.LittleEndian.PutUint64(p[n:], extismInputLoadU64(memory.ExtismPointer(r.offset+n)))
+	}
+	for ; n < len(p); n++ {
+		p[n] = extismInputLoadU8(memory.ExtismPointer(r.offset + n))
+	}
+	r.offset += n
+	return n, nil
+}
+
+// Record is an item of the input, read by `InputLines`, `InputNDJSON` or `InputFrames`, or by
+// package github.com/extism/go-pdk/csv.
+type Record[T any] struct {
+	// Line is the number of the line the record starts at, from 1, or the number of the frame for
+	// `InputFrames`.
+	Line  int
+	Value T
+}
+
+// InputLines returns an iterator over the lines of the input, without their "\n" or "\r\n"
+// terminator. With Go 1.23 or later, it can be ranged over:
+//
+//	for line, err := range pdk.InputLines() {
+//		if err != nil {
+//			pdk.SetError(err)
+//			return 1
+//		}
+//		pdk.Log(pdk.LogDebug, line.Value)
+//	}
+//
+// The input is read as the iteration goes, so only the current line is held in memory. Reading
+// stops at the first error.
+func InputLines() func(yield func(Record[string], error) bool) {
+	return func(yield func(Record[string], error) bool) {
+		lines(func(number int, line []byte, err error) bool {
+			return yield(Record[string]{Line: number, Value: string(line)}, err)
+		})
+	}
+}
+
+// InputNDJSON returns an iterator over the values of the newline-delimited JSON input, decoding
+// each line into a `T`. Blank lines are skipped.
+//
+// A line that fails to decode yields a `DecodeError` along with its number, and the iteration
+// goes on with the next line unless the loop breaks.
+func InputNDJSON[T any]() func(yield func(Record[T], error) bool) {
+	return func(yield func(Record[T], error) bool) {
+		lines(func(number int, line []byte, err error) bool {
+			record := Record[T]{Line: number}
+			if err != nil {
+				return yield(record, err)
+			}
+			if len(bytes.TrimSpace(line)) == 0 {
+				return true
+			}
+			if err := unmarshalJSON(line, &record.Value); err != nil {
+				return yield(record, &DecodeError{Type: "JSON", Length: len(line), Err: err})
+			}
+			return yield(record, nil)
+		})
+	}
+}
+
+// InputFrames returns an iterator over the frames of the length-prefixed input, for records that
+// may contain newlines, such as binary ones. Each frame is its length, as a little-endian uint32,
+// followed by that many bytes. The `Record.Line` of a frame is its number, from 1.
+//
+// The input is read as the iteration goes. A truncated frame yields io.ErrUnexpectedEOF and stops
+// the iteration.
+func InputFrames() func(yield func(Record[[]byte], error) bool) {
+	return func(yield func(Record[[]byte], error) bool) {
+		r := bufio.NewReader(InputReader())
+		var prefix [4]byte
+		for number := 1; ; number++ {
+			record := Record[[]byte]{Line: number}
+			if _, err := io.ReadFull(r, prefix[:]); err == io.EOF {
+				return
+			} else if err != nil {
+				yield(record, err)
+				return
+			}
+
+			// a corrupt length can't be larger than the input, which bounds the allocation
+			length := binary.LittleEndian.Uint32(prefix[:])
+			if uint64(length) > extismInputLength() {
+				yield(record, io.ErrUnexpectedEOF)
+				return
+			}
+			record.Value = make([]byte, length)
+			if _, err := io.ReadFull(r, record.Value); err != nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				yield(Record[[]byte]{Line: number}, err)
+				return
+			}
+
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+// lines calls `line` with each line of the input and its number, until it returns false, or with
+// the error that stopped reading.
+func lines(line func(number int, text []byte, err error) bool) {
+	r := bufio.NewReader(InputReader())
+	for number := 1; ; number++ {
+		text, err := r.ReadSlice('\n')
+		if err == bufio.ErrBufferFull {
+			// the line is longer than the buffer: collect it in a slice of its own
+			text = append([]byte(nil), text...)
+			for err == bufio.ErrBufferFull {
+				var more []byte
+				more, err = r.ReadSlice('\n')
+				text = append(text, more...)
+			}
+		}
+		if err != nil && err != io.EOF {
+			line(number, nil, err)
+			return
+		}
+		if err == io.EOF && len(text) == 0 {
+			return
+		}
+
+		text = bytes.TrimSuffix(text, []byte{'\n'})
+		text = bytes.TrimSuffix(text, []byte{'\r'})
+		if !line(number, text, nil) || err == io.EOF {
+			return
+		}
+	}
+}
@@ -0,0 +1,125 @@
+//go:build !wasm
+
+package pdk
+
+import (
+	"encoding/binary"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/extism/go-pdk/native"
+)
+
+// collect returns the records and errors yielded by `seq`, which is ranged over without the Go
+// 1.23 range-over-func syntax.
+func collect[T any](seq func(yield func(Record[T], error) bool)) ([]Record[T], []error) {
+	var records []Record[T]
+	var errs []error
+	seq(func(record Record[T], err error) bool {
+		records = append(records, record)
+		errs = append(errs, err)
+		return true
+	})
+	return records, errs
+}
+
+func TestInputLines(t *testing.T) {
+	long := strings.Repeat("x", 10000)
+	native.Call([]byte("one\r\n\ntwo\n"+long), func() int32 {
+		records, errs := collect(InputLines())
+		want := []Record[string]{{1, "one"}, {2, ""}, {3, "two"}, {4, long}}
+		if !reflect.DeepEqual(records, want) || errs[len(errs)-1] != nil {
+			t.Errorf("InputLines() = %.40v, %v", records, errs)
+		}
+		return 0
+	})
+}
+
+// point decodes itself, so that the test runs with the nojson tag too.
+type point struct {
+	X int
+}
+
+func (p *point) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(text, `{"x":`) || !strings.HasSuffix(text, "}") {
+		return io.ErrUnexpectedEOF
+	}
+	x := text[len(`{"x":`) : len(text)-1]
+	var err error
+	p.X, err = strconv.Atoi(x)
+	return err
+}
+
+func TestInputNDJSON(t *testing.T) {
+	native.Call([]byte("{\"x\":1}\n\n  \n{\"x\":2}\nnope\n{\"x\":3}"), func() int32 {
+		records, errs := collect(InputNDJSON[point]())
+		if len(records) != 4 {
+			t.Fatalf("InputNDJSON() yielded %d records, want 4", len(records))
+		}
+		for i, want := range []struct{ line, x int }{{1, 1}, {4, 2}, {5, 0}, {6, 3}} {
+			if records[i].Line != want.line || records[i].Value.X != want.x {
+				t.Errorf("record %d = %+v, want line %d, x %d", i, records[i], want.line, want.x)
+			}
+		}
+		if _, ok := errs[2].(*DecodeError); !ok {
+			t.Errorf("error of line 5 = %v, want a DecodeError", errs[2])
+		}
+		return 0
+	})
+}
+
+func frames(payloads ...string) []byte {
+	var data []byte
+	for _, payload := range payloads {
+		data = binary.LittleEndian.AppendUint32(data, uint32(len(payload)))
+		data = append(data, payload...)
+	}
+	return data
+}
+
+func TestInputFrames(t *testing.T) {
+	long := strings.Repeat("y", 5000)
+	native.Call(frames("a\nb", "", long, "c"), func() int32 {
+		records, errs := collect(InputFrames())
+		want := []Record[[]byte]{{1, []byte("a\nb")}, {2, []byte{}}, {3, []byte(long)}, {4, []byte("c")}}
+		if !reflect.DeepEqual(records, want) {
+			t.Errorf("InputFrames() = %.40v", records)
+		}
+		for _, err := range errs {
+			if err != nil {
+				t.Errorf("InputFrames() error: %v", err)
+			}
+		}
+		return 0
+	})
+
+	for _, input := range [][]byte{
+		frames("ok", "truncated")[:14],
+		append(frames("ok"), 1, 0),
+		append(frames("ok"), 0xff, 0xff, 0xff, 0xff),
+	} {
+		native.Call(input, func() int32 {
+			records, errs := collect(InputFrames())
+			if len(records) != 2 || string(records[0].Value) != "ok" || errs[1] != io.ErrUnexpectedEOF {
+				t.Errorf("InputFrames() on % x = %v, %v, want ok then io.ErrUnexpectedEOF", input, records, errs)
+			}
+			return 0
+		})
+	}
+}
+
+func TestInputFramesEnvelope(t *testing.T) {
+	input := append([]byte("extism-envelope/1\nContent-Type: application/octet-stream\n\n"), frames("in", "envelope")...)
+	native.Call(input, func() int32 {
+		InputEnvelope()
+		records, _ := collect(InputFrames())
+		if len(records) != 2 || string(records[1].Value) != "envelope" {
+			t.Errorf("InputFrames() = %q, want the frames of the payload", records)
+		}
+		return 0
+	})
+}
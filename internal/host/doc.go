@@ -0,0 +1,4 @@
+// Package host is a local implementation of the Extism host functions, backing the PDK when a
+// plugin is built as a native binary (for any architecture but wasm) to be run and debugged
+// without a Wasm runtime. See package github.com/extism/go-pdk/native.
+package host
@@ -0,0 +1,124 @@
+//go:build !wasm
+
+package host
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// exportConfigKey is pdk.ExportConfigKey, set by the -export flag.
+const exportConfigKey = "extism_export"
+
+// Start configures the host from the command-line flags, unless it was already configured with
+// Configure. It exits if the flags are invalid.
+//
+// Flags:
+//
+//	-input string         plugin input
+//	-input-file path      file to read the plugin input from, "-" for stdin
+//	-config key=value     config value, can be repeated
+//	-config-file path     JSON object of config values
+//	-vars path            JSON file the vars are persisted to between runs
+//	-log-level level      trace, debug, info, warn or error (default info)
+//	-export name          export to call, when several are registered
+func Start() {
+	state.Lock()
+	configured := state.configured
+	state.Unlock()
+	if configured {
+		return
+	}
+
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := Configure(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to load vars:", err)
+		os.Exit(1)
+	}
+}
+
+// configFlag collects repeated -config key=value flags.
+type configFlag map[string]string
+
+func (c configFlag) String() string {
+	return ""
+}
+
+func (c configFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	c[key] = val
+	return nil
+}
+
+func parseFlags(args []string) (Options, error) {
+	config := configFlag{}
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	input := flags.String("input", "", "plugin input")
+	inputFile := flags.String("input-file", "", `file to read the plugin input from, "-" for stdin`)
+	flags.Var(config, "config", "config value as key=value, can be repeated")
+	configFile := flags.String("config-file", "", "JSON object of config values")
+	varsFile := flags.String("vars", "", "JSON file the vars are persisted to between runs")
+	logLevel := flags.String("log-level", "info", "trace, debug, info, warn or error")
+	export := flags.String("export", "", "export to call, when several are registered")
+	if err := flags.Parse(args); err != nil {
+		return Options{}, err
+	}
+
+	opts := Options{
+		Input:    []byte(*input),
+		Config:   map[string]string{},
+		VarsFile: *varsFile,
+	}
+
+	switch *inputFile {
+	case "":
+	case "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Input = data
+	default:
+		data, err := os.ReadFile(*inputFile)
+		if err != nil {
+			return Options{}, err
+		}
+		opts.Input = data
+	}
+
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return Options{}, err
+		}
+		if err := json.Unmarshal(data, &opts.Config); err != nil {
+			return Options{}, fmt.Errorf("invalid config file %s: %w", *configFile, err)
+		}
+	}
+	for key, value := range config {
+		opts.Config[key] = value
+	}
+	if *export != "" {
+		opts.Config[exportConfigKey] = *export
+	}
+
+	level, err := ParseLogLevel(*logLevel)
+	if err != nil {
+		return Options{}, err
+	}
+	opts.LogLevel = level
+
+	return opts, nil
+}
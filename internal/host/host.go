@@ -0,0 +1,355 @@
+//go:build !wasm
+
+package host
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Options configures the local host.
+type Options struct {
+	// Input is the plugin input.
+	Input []byte
+	// Config holds the plugin config.
+	Config map[string]string
+	// VarsFile is the path of a JSON file the plugin vars are loaded from, and saved to by Flush,
+	// so that they persist between runs. Vars are kept in memory only if it is empty.
+	VarsFile string
+	// LogLevel is the minimum level of the logged messages, from 0 (trace) to 4 (error).
+	LogLevel int32
+	// Client sends the HTTP requests of the plugin, http.DefaultClient if nil.
+	Client *http.Client
+	// Stdout receives the plugin output and Stderr its error and logs, os.Stdout and os.Stderr
+	// if nil.
+	Stdout, Stderr io.Writer
+}
+
+var logLevels = [...]string{"trace", "debug", "info", "warn", "error"}
+
+// state is the local host state. Host functions can be called from several goroutines.
+var state struct {
+	sync.Mutex
+	opts       Options
+	configured bool
+
+	// heap holds the memory blocks, at 8-byte aligned offsets; blocks maps the offset of each
+	// allocated block to its length. Offset 0 is reserved as the null block.
+	heap   []byte
+	blocks map[uint64]uint64
+
+	output    []byte
+	outputSet bool
+	err       string
+
+	vars map[string][]byte
+
+	httpStatus  int32
+	httpHeaders map[string]string
+}
+
+func init() {
+	state.blocks = map[uint64]uint64{}
+	state.heap = make([]byte, 8)
+	state.vars = map[string][]byte{}
+	state.opts.LogLevel = 2
+}
+
+// Configure replaces the host options, loading the vars from `opts.VarsFile` if it exists.
+func Configure(opts Options) error {
+	state.Lock()
+	defer state.Unlock()
+
+	state.opts = opts
+	state.configured = true
+	state.vars = map[string][]byte{}
+	if opts.VarsFile == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(opts.VarsFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &state.vars)
+}
+
+// Begin starts a new call with `input`. Like an Extism host at the start of each call, it resets
+// the memory blocks, the output, the error and the last HTTP response, while the config and vars
+// persist.
+func Begin(input []byte) {
+	state.Lock()
+	defer state.Unlock()
+
+	state.opts.Input = input
+	state.heap = make([]byte, 8)
+	state.blocks = map[uint64]uint64{}
+	state.output = nil
+	state.outputSet = false
+	state.err = ""
+	state.httpStatus = 0
+	state.httpHeaders = nil
+}
+
+// Result returns the output and the error set by the plugin during the call.
+func Result() ([]byte, string) {
+	state.Lock()
+	defer state.Unlock()
+	return state.output, state.err
+}
+
+// ParseLogLevel returns the level called `name`, e.g. "info".
+func ParseLogLevel(name string) (int32, error) {
+	for level, levelName := range logLevels {
+		if strings.EqualFold(name, levelName) {
+			return int32(level), nil
+		}
+	}
+	return 0, errors.New("unknown log level " + strconv.Quote(name) + ", expected one of: " + strings.Join(logLevels[:], ", "))
+}
+
+// Flush writes the output set by the plugin to Stdout and its error to Stderr, and saves the vars
+// to the vars file, if any.
+func Flush() error {
+	state.Lock()
+	defer state.Unlock()
+
+	if state.outputSet {
+		if _, err := stdout().Write(state.output); err != nil {
+			return err
+		}
+	}
+	if state.err != "" {
+		io.WriteString(stderr(), state.err+"\n")
+	}
+
+	if state.opts.VarsFile == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(state.vars, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(state.opts.VarsFile, data, 0o644)
+}
+
+// Exit flushes the host, then exits with `code`.
+func Exit(code int32) {
+	if err := Flush(); err != nil {
+		io.WriteString(stderr(), err.Error()+"\n")
+		if code == 0 {
+			code = 1
+		}
+	}
+	os.Exit(int(code))
+}
+
+func stdout() io.Writer {
+	if state.opts.Stdout != nil {
+		return state.opts.Stdout
+	}
+	return os.Stdout
+}
+
+func stderr() io.Writer {
+	if state.opts.Stderr != nil {
+		return state.opts.Stderr
+	}
+	return os.Stderr
+}
+
+// Alloc allocates a zeroed block of `length` bytes and returns its offset.
+func Alloc(length uint64) uint64 {
+	state.Lock()
+	defer state.Unlock()
+	return alloc(length)
+}
+
+func alloc(length uint64) uint64 {
+	// empty blocks still take 8 bytes, so that every block has its own offset
+	size := (length + 7) &^ 7
+	if size == 0 {
+		size = 8
+	}
+	offset := uint64(len(state.heap))
+	state.heap = append(state.heap, make([]byte, size)...)
+	state.blocks[offset] = length
+	return offset
+}
+
+// allocBytes allocates a block holding `data`.
+func allocBytes(data []byte) uint64 {
+	offset := alloc(uint64(len(data)))
+	copy(state.heap[offset:], data)
+	return offset
+}
+
+// Free releases the block at `offset`. Its memory isn't reused.
+func Free(offset uint64) {
+	state.Lock()
+	defer state.Unlock()
+	delete(state.blocks, offset)
+}
+
+// Length returns the length of the block at `offset`, or 0 if there is none.
+func Length(offset uint64) uint64 {
+	state.Lock()
+	defer state.Unlock()
+	return state.blocks[offset]
+}
+
+// block returns the data of the block at `offset`, or nil if there is none.
+func block(offset uint64) []byte {
+	length, ok := state.blocks[offset]
+	if !ok {
+		return nil
+	}
+	return state.heap[offset : offset+length]
+}
+
+// span returns the `n` heap bytes at `offset`, panicking like a Wasm trap when out of bounds.
+func span(offset, n uint64) []byte {
+	if offset == 0 || offset+n > uint64(len(state.heap)) {
+		panic("extism host: memory access out of bounds at offset " + strconv.FormatUint(offset, 10))
+	}
+	return state.heap[offset : offset+n]
+}
+
+func LoadU8(offset uint64) uint8 {
+	state.Lock()
+	defer state.Unlock()
+	return span(offset, 1)[0]
+}
+
+func LoadU64(offset uint64) uint64 {
+	state.Lock()
+	defer state.Unlock()
+	return binary.LittleEndian.Uint64(span(offset, 8))
+}
+
+func StoreU8(offset uint64, v uint8) {
+	state.Lock()
+	defer state.Unlock()
+	span(offset, 1)[0] = v
+}
+
+func StoreU64(offset uint64, v uint64) {
+	state.Lock()
+	defer state.Unlock()
+	binary.LittleEndian.PutUint64(span(offset, 8), v)
+}
+
+// LoadBytes copies the heap bytes at `offset` into `dest`, like the load_bytes import of the
+// extism_bulk_memory build tag.
+func LoadBytes(offset uint64, dest []byte) {
+	state.Lock()
+	defer state.Unlock()
+	copy(dest, span(offset, uint64(len(dest))))
+}
+
+// StoreBytes copies `src` to the heap at `offset`, like the store_bytes import of the
+// extism_bulk_memory build tag.
+func StoreBytes(offset uint64, src []byte) {
+	state.Lock()
+	defer state.Unlock()
+	copy(span(offset, uint64(len(src))), src)
+}
+
+func InputLength() uint64 {
+	state.Lock()
+	defer state.Unlock()
+	return uint64(len(state.opts.Input))
+}
+
+func InputLoadU8(offset uint64) uint8 {
+	state.Lock()
+	defer state.Unlock()
+	return state.opts.Input[offset]
+}
+
+func InputLoadU64(offset uint64) uint64 {
+	state.Lock()
+	defer state.Unlock()
+	return binary.LittleEndian.Uint64(state.opts.Input[offset : offset+8])
+}
+
+// OutputSet copies the `length` bytes at `offset` as the plugin output.
+func OutputSet(offset, length uint64) {
+	state.Lock()
+	defer state.Unlock()
+	state.output = append([]byte(nil), span(offset, length)...)
+	state.outputSet = true
+}
+
+// ErrorSet copies the block at `offset` as the plugin error.
+func ErrorSet(offset uint64) {
+	state.Lock()
+	defer state.Unlock()
+	state.err = string(block(offset))
+}
+
+// ConfigGet returns a new block holding the config value keyed by the block at `offset`, or 0 if
+// there is none.
+func ConfigGet(offset uint64) uint64 {
+	state.Lock()
+	defer state.Unlock()
+
+	value, ok := state.opts.Config[string(block(offset))]
+	if !ok {
+		return 0
+	}
+	return allocBytes([]byte(value))
+}
+
+// VarGet returns a new block holding the var keyed by the block at `offset`, or 0 if there is none.
+func VarGet(offset uint64) uint64 {
+	state.Lock()
+	defer state.Unlock()
+
+	value, ok := state.vars[string(block(offset))]
+	if !ok {
+		return 0
+	}
+	return allocBytes(value)
+}
+
+// VarSet sets the var keyed by the block at `offset` to the block at `valueOffset`, or removes it
+// if `valueOffset` is 0.
+func VarSet(offset, valueOffset uint64) {
+	state.Lock()
+	defer state.Unlock()
+
+	key := string(block(offset))
+	if valueOffset == 0 {
+		delete(state.vars, key)
+		return
+	}
+	state.vars[key] = append([]byte(nil), block(valueOffset)...)
+}
+
+// Log writes the message in the block at `offset` to Stderr, if `level` is enabled.
+func Log(level int32, offset uint64) {
+	state.Lock()
+	defer state.Unlock()
+
+	if level < state.opts.LogLevel {
+		return
+	}
+	io.WriteString(stderr(), "["+logLevels[level]+"] "+string(block(offset))+"\n")
+}
+
+func LogLevel() int32 {
+	state.Lock()
+	defer state.Unlock()
+	return state.opts.LogLevel
+}
@@ -0,0 +1,94 @@
+//go:build !wasm
+
+package host
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// HTTPRequest sends the request described by the JSON block at `offset` with the body at
+// `bodyOffset` (0 means no body), and returns a new block holding the response body, or 0 if it
+// is empty. A request that can't be sent panics, as the call fails on an Extism host.
+func HTTPRequest(offset, bodyOffset uint64) uint64 {
+	state.Lock()
+	var meta struct {
+		URL     string            `json:"url"`
+		Method  string            `json:"method"`
+		Headers map[string]string `json:"headers"`
+	}
+	metaErr := json.Unmarshal(block(offset), &meta)
+	var body io.Reader
+	if bodyOffset != 0 {
+		body = bytes.NewReader(append([]byte(nil), block(bodyOffset)...))
+	}
+	client := state.opts.Client
+	state.Unlock()
+
+	if metaErr != nil {
+		panic("extism host: invalid HTTP request: " + metaErr.Error())
+	}
+	if meta.Method == "" {
+		meta.Method = "GET"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequest(meta.Method, meta.URL, body)
+	if err != nil {
+		panic("extism host: invalid HTTP request: " + err.Error())
+	}
+	for name, value := range meta.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		panic("extism host: HTTP request failed: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		panic("extism host: HTTP request failed: " + err.Error())
+	}
+
+	headers := map[string]string{}
+	for name, values := range resp.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+
+	state.Lock()
+	defer state.Unlock()
+
+	state.httpStatus = int32(resp.StatusCode)
+	state.httpHeaders = headers
+	if len(data) == 0 {
+		return 0
+	}
+	return allocBytes(data)
+}
+
+// HTTPStatusCode returns the status code of the last response.
+func HTTPStatusCode() int32 {
+	state.Lock()
+	defer state.Unlock()
+	return state.httpStatus
+}
+
+// HTTPHeaders returns a new block holding the JSON-encoded headers of the last response, or 0 if
+// no request was sent.
+func HTTPHeaders() uint64 {
+	state.Lock()
+	defer state.Unlock()
+
+	if state.httpHeaders == nil {
+		return 0
+	}
+	data, _ := json.Marshal(state.httpHeaders)
+	return allocBytes(data)
+}
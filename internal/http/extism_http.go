@@ -1,3 +1,5 @@
+//go:build wasm
+
 package http
 
 import "github.com/extism/go-pdk/internal/memory"
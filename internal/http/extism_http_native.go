@@ -0,0 +1,22 @@
+//go:build !wasm
+
+package http
+
+import (
+	"github.com/extism/go-pdk/internal/host"
+	"github.com/extism/go-pdk/internal/memory"
+)
+
+// The host functions of extism_http.go, implemented by the local host of native builds.
+
+func ExtismHTTPRequest(request, body memory.ExtismPointer) memory.ExtismPointer {
+	return memory.ExtismPointer(host.HTTPRequest(uint64(request), uint64(body)))
+}
+
+func ExtismHTTPStatusCode() int32 {
+	return host.HTTPStatusCode()
+}
+
+func ExtismHTTPHeaders() memory.ExtismPointer {
+	return memory.ExtismPointer(host.HTTPHeaders())
+}
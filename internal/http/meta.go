@@ -0,0 +1,47 @@
+package http
+
+import "github.com/extism/go-pdk/jsonlite"
+
+// The HTTP request metadata and response headers exchanged with the host are small JSON objects
+// of strings, encoded and decoded with jsonlite so that the core PDK doesn't depend on
+// encoding/json.
+
+// EncodeRequest returns the JSON request metadata expected by `http_request`:
+// {"url": ..., "method": ..., "headers": {...}}.
+func EncodeRequest(url, method string, headers map[string]string) []byte {
+	e := jsonlite.NewEncoder()
+	e.BeginObject()
+	e.Key("url")
+	e.String(url)
+	e.Key("method")
+	e.String(method)
+	e.Key("headers")
+	e.BeginObject()
+	for _, name := range jsonlite.SortedKeys(headers) {
+		e.Key(name)
+		e.String(headers[name])
+	}
+	e.EndObject()
+	e.EndObject()
+
+	data, _ := e.Bytes()
+	return data
+}
+
+// DecodeHeaders decodes the JSON object of strings returned by `http_headers`.
+func DecodeHeaders(data []byte) (map[string]string, error) {
+	headers := map[string]string{}
+	d := jsonlite.NewDecoder(data)
+	err := d.Object(func(name string) error {
+		var value string
+		if err := jsonlite.String(d, &value); err != nil {
+			return err
+		}
+		headers[name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return headers, d.End()
+}
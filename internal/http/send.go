@@ -0,0 +1,36 @@
+package http
+
+import (
+	"sync"
+
+	"github.com/extism/go-pdk/internal/memory"
+)
+
+// mu serializes the host calls of a request: the status code and headers are those of the
+// last-sent request, which another goroutine could replace in between.
+var mu sync.Mutex
+
+// Response is what the host returns for a request sent with `Send`.
+type Response struct {
+	// Body is the offset of the response body, 0 if there is none.
+	Body memory.ExtismPointer
+	// Status is the response status code.
+	Status int32
+	// Headers is the offset of the JSON-encoded response headers, 0 if there are none. The
+	// caller must free it.
+	Headers memory.ExtismPointer
+}
+
+// Send sends the HTTP `request` to the host with the provided `body` (0 means no body), and
+// returns the response body, status code and headers together.
+func Send(request, body memory.ExtismPointer) Response {
+	mu.Lock()
+	defer mu.Unlock()
+
+	offset := ExtismHTTPRequest(request, body)
+	return Response{
+		Body:    offset,
+		Status:  ExtismHTTPStatusCode(),
+		Headers: ExtismHTTPHeaders(),
+	}
+}
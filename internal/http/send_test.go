@@ -0,0 +1,66 @@
+//go:build !wasm
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/extism/go-pdk/internal/memory"
+)
+
+// TestSendConcurrent sends requests from many goroutines, each response having its own status code
+// and headers, which must not get mixed up between the requests. The request blocks are allocated
+// and stored concurrently too.
+func TestSendConcurrent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Query().Get("id")
+		n, _ := strconv.Atoi(id)
+		w.Header().Set("X-Id", id)
+		w.WriteHeader(200 + n%4)
+		w.Write([]byte(id))
+	}))
+	defer server.Close()
+
+	// interleave the host calls of the goroutines, even on a single CPU
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+
+	const goroutines, requests = 32, 64
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < requests; i++ {
+				n := g*requests + i
+				id := strconv.Itoa(n)
+
+				req := memory.AllocateBytes(EncodeRequest(server.URL+"/?id="+id, "GET", nil))
+				resp := Send(memory.ExtismPointer(req.Offset()), 0)
+				req.Free()
+
+				if want := int32(200 + n%4); resp.Status != want {
+					t.Errorf("request %s: status %d, want %d", id, resp.Status, want)
+				}
+
+				headers := memory.NewMemory(resp.Headers, memory.ExtismLength(resp.Headers))
+				decoded, err := DecodeHeaders(headers.ReadBytes())
+				headers.Free()
+				if err != nil || decoded["x-id"] != id {
+					t.Errorf("request %s: headers %v (%v), want x-id %s", id, decoded, err, id)
+				}
+
+				body := memory.NewMemory(resp.Body, memory.ExtismLength(resp.Body))
+				if got := string(body.ReadBytes()); got != id {
+					t.Errorf("request %s: body %q", id, got)
+				}
+				body.Free()
+			}
+		}(g)
+	}
+	wg.Wait()
+}
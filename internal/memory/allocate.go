@@ -9,6 +9,10 @@ func Allocate(length int) Memory {
 }
 
 // AllocateBytes allocates and saves the `data` into Memory on the host.
+//
+// Unlike the `http_request`, `http_status_code` and `http_headers` sequence, the `alloc` and
+// `store` calls need no lock against other goroutines: each host call is atomic, and the stores
+// only touch the block returned by `alloc`, which no other goroutine knows of yet.
 func AllocateBytes(data []byte) Memory {
 	clength := uint64(len(data))
 	offset := ExtismAlloc(clength)
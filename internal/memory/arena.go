@@ -0,0 +1,71 @@
+package memory
+
+// Arena tracks the blocks allocated through it, so they can all be released at once with Reset
+// or Release.
+//
+// Blocks can't be carved out of a larger host block, since the host resolves a block, and its
+// length, from the offset it was allocated at. An Arena instead allocates from a `Pool`, to which
+// Reset returns its blocks for reuse, while Release frees them on the host.
+//
+// Like its Pool, an Arena must not be kept across calls, since the host resets its memory at the
+// start of every call: it must be released before the export returns. Blocks which must outlive
+// the Arena, such as those passed to `output_set` or used as var values, must be detached from it
+// with Keep.
+type Arena struct {
+	pool   *Pool
+	blocks []Memory
+}
+
+// NewArena returns an `Arena` allocating from, and releasing to, `pool`, which should not be shared
+// with arenas living longer.
+func NewArena(pool *Pool) *Arena {
+	return &Arena{pool: pool}
+}
+
+// Allocate allocates `length` uninitialized bytes, released on Reset.
+func (a *Arena) Allocate(length int) Memory {
+	mem := a.pool.Allocate(length)
+	a.blocks = append(a.blocks, mem)
+	return mem
+}
+
+// AllocateBytes allocates and saves `data`, released on Reset.
+func (a *Arena) AllocateBytes(data []byte) Memory {
+	mem := a.pool.AllocateBytes(data)
+	a.blocks = append(a.blocks, mem)
+	return mem
+}
+
+// Track makes the arena release `mem`, a block allocated elsewhere, on Reset.
+func (a *Arena) Track(mem Memory) {
+	a.blocks = append(a.blocks, mem)
+}
+
+// Keep detaches `mem` from the arena, so Reset doesn't release it and freeing it becomes the
+// caller's responsibility (or the host's, once handed over).
+func (a *Arena) Keep(mem Memory) {
+	for i, block := range a.blocks {
+		if block.offset == mem.offset {
+			a.blocks = append(a.blocks[:i], a.blocks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Reset releases all the blocks allocated through the arena and not kept.
+func (a *Arena) Reset() {
+	for _, mem := range a.blocks {
+		a.pool.Free(mem)
+	}
+	a.blocks = a.blocks[:0]
+}
+
+// Release frees all the blocks allocated through the arena and not kept, along with those pooled
+// by Reset, on the host.
+func (a *Arena) Release() {
+	for _, mem := range a.blocks {
+		mem.Free()
+	}
+	a.blocks = a.blocks[:0]
+	a.pool.Drain()
+}
@@ -0,0 +1,20 @@
+//go:build !extism_bulk_memory && !(wasm && extism_shared_memory)
+
+package memory
+
+// Shared reports whether host memory blocks are directly addressable by the plugin.
+const Shared = false
+
+// Load copies the host memory at `offset` into `buf`, 8 bytes per host call.
+func Load(offset ExtismPointer, buf []byte) {
+	loadWords(offset, buf)
+}
+
+// Store copies `buf` into the host memory at `offset`, 8 bytes per host call.
+func Store(offset ExtismPointer, buf []byte) {
+	storeWords(offset, buf)
+}
+
+func view(offset ExtismPointer, length uint64) ([]byte, bool) {
+	return nil, false
+}
@@ -0,0 +1,28 @@
+//go:build extism_bulk_memory && !(wasm && extism_shared_memory)
+
+package memory
+
+import "unsafe"
+
+// Shared reports whether host memory blocks are directly addressable by the plugin.
+const Shared = false
+
+// Load copies the host memory at `offset` into `buf` with a single host call.
+func Load(offset ExtismPointer, buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+	extismLoadBytes(offset, unsafe.Pointer(&buf[0]), uint64(len(buf)))
+}
+
+// Store copies `buf` into the host memory at `offset` with a single host call.
+func Store(offset ExtismPointer, buf []byte) {
+	if len(buf) == 0 {
+		return
+	}
+	extismStoreBytes(offset, unsafe.Pointer(&buf[0]), uint64(len(buf)))
+}
+
+func view(offset ExtismPointer, length uint64) ([]byte, bool) {
+	return nil, false
+}
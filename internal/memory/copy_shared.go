@@ -0,0 +1,37 @@
+//go:build wasm && extism_shared_memory
+
+package memory
+
+// No released Extism runtime shares its memory with the plugin yet: the extism_shared_memory build
+// tag is for hosts that do, such as embedders running the kernel in the plugin memory.
+
+import "unsafe"
+
+// Shared reports whether host memory blocks are directly addressable by the plugin.
+const Shared = true
+
+// Load copies the host memory at `offset` into `buf`, without any host call.
+func Load(offset ExtismPointer, buf []byte) {
+	src, _ := view(offset, uint64(len(buf)))
+	copy(buf, src)
+}
+
+// Store copies `buf` into the host memory at `offset`, without any host call.
+func Store(offset ExtismPointer, buf []byte) {
+	dst, _ := view(offset, uint64(len(buf)))
+	copy(dst, buf)
+}
+
+// view returns the host memory at `offset` as a slice of the plugin memory, since block offsets
+// are addresses in the plugin memory when it is shared with the host. Like the kernel traps on
+// an access out of its memory, it panics on a non-empty access at offset 0, the null block,
+// rather than silently loading zeros or dropping the write.
+func view(offset ExtismPointer, length uint64) ([]byte, bool) {
+	if length == 0 {
+		return nil, true
+	}
+	if offset == 0 {
+		panic("extism: memory access out of bounds at offset 0")
+	}
+	return unsafe.Slice((*byte)(unsafe.Add(nil, uintptr(offset))), length), true
+}
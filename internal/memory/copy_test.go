@@ -0,0 +1,65 @@
+//go:build !wasm
+
+package memory
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+)
+
+// The copies run against the local host of native builds. Built with the extism_bulk_memory tag,
+// Load and Store copy whole blocks, and their benchmarks compare them to the per-word copies of
+// loadWords and storeWords:
+//
+//	go test -run '^$' -bench . -tags extism_bulk_memory ./internal/memory
+
+var copySizes = []int{7, 64, 4 << 10, 1 << 20}
+
+func TestLoadStore(t *testing.T) {
+	for _, size := range copySizes {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i * 31)
+		}
+
+		mem := AllocateBytes(data)
+		got := mem.ReadBytes()
+		mem.Free()
+		if !bytes.Equal(got, data) {
+			t.Errorf("%d bytes: loaded data differs from the stored data", size)
+		}
+	}
+}
+
+func benchmarkCopy(b *testing.B, copyFn func(ExtismPointer, []byte)) {
+	for _, size := range copySizes {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			mem := Allocate(size)
+			defer mem.Free()
+			buf := make([]byte, size)
+
+			b.SetBytes(int64(size))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				copyFn(mem.offset, buf)
+			}
+		})
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	benchmarkCopy(b, Load)
+}
+
+func BenchmarkLoadWords(b *testing.B) {
+	benchmarkCopy(b, loadWords)
+}
+
+func BenchmarkStore(b *testing.B) {
+	benchmarkCopy(b, Store)
+}
+
+func BenchmarkStoreWords(b *testing.B) {
+	benchmarkCopy(b, storeWords)
+}
@@ -1,3 +1,5 @@
+//go:build wasm
+
 package memory
 
 // extismStoreU8 stores the byte `v` at location `offset` in the host memory block.
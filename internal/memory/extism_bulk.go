@@ -0,0 +1,20 @@
+//go:build wasm && extism_bulk_memory && !extism_shared_memory
+
+package memory
+
+import "unsafe"
+
+// The bulk copy imports of the extism_bulk_memory build tag. No released Extism runtime provides
+// them yet: a module built with the tag only instantiates on a host that does.
+
+// extismLoadBytes copies `length` bytes at location `offset` in the host memory block to `dest`
+// in the plugin memory.
+//
+//go:wasmimport extism:host/env load_bytes
+func extismLoadBytes(offset ExtismPointer, dest unsafe.Pointer, length uint64)
+
+// extismStoreBytes copies `length` bytes from `src` in the plugin memory to location `offset` in
+// the host memory block.
+//
+//go:wasmimport extism:host/env store_bytes
+func extismStoreBytes(offset ExtismPointer, src unsafe.Pointer, length uint64)
@@ -0,0 +1,19 @@
+//go:build !wasm && extism_bulk_memory
+
+package memory
+
+import (
+	"unsafe"
+
+	"github.com/extism/go-pdk/internal/host"
+)
+
+// The host functions of extism_bulk.go, implemented by the local host of native builds.
+
+func extismLoadBytes(offset ExtismPointer, dest unsafe.Pointer, length uint64) {
+	host.LoadBytes(uint64(offset), unsafe.Slice((*byte)(dest), length))
+}
+
+func extismStoreBytes(offset ExtismPointer, src unsafe.Pointer, length uint64) {
+	host.StoreBytes(uint64(offset), unsafe.Slice((*byte)(src), length))
+}
@@ -0,0 +1,39 @@
+//go:build !wasm
+
+package memory
+
+import "github.com/extism/go-pdk/internal/host"
+
+// The host functions of extism.go, implemented by the local host of native builds.
+
+func ExtismStoreU8(offset ExtismPointer, v uint8) {
+	host.StoreU8(uint64(offset), v)
+}
+
+func ExtismLoadU8(offset ExtismPointer) uint8 {
+	return host.LoadU8(uint64(offset))
+}
+
+func ExtismStoreU64(offset ExtismPointer, v uint64) {
+	host.StoreU64(uint64(offset), v)
+}
+
+func ExtismLoadU64(offset ExtismPointer) uint64 {
+	return host.LoadU64(uint64(offset))
+}
+
+func ExtismLengthUnsafe(offset ExtismPointer) uint64 {
+	return host.Length(uint64(offset))
+}
+
+func ExtismLength(offset ExtismPointer) uint64 {
+	return host.Length(uint64(offset))
+}
+
+func ExtismAlloc(length uint64) ExtismPointer {
+	return ExtismPointer(host.Alloc(length))
+}
+
+func ExtismFree(offset ExtismPointer) {
+	host.Free(uint64(offset))
+}
@@ -1,40 +1,5 @@
 package memory
 
-import "encoding/binary"
-
-func Load(offset ExtismPointer, buf []byte) {
-	length := len(buf)
-	chunkCount := length >> 3
-
-	for chunkIdx := 0; chunkIdx < chunkCount; chunkIdx++ {
-		i := chunkIdx << 3
-		binary.LittleEndian.PutUint64(buf[i:i+8], ExtismLoadU64(offset+ExtismPointer(i)))
-	}
-
-	remainder := length & 7
-	remainderOffset := chunkCount << 3
-	for index := remainderOffset; index < (remainder + remainderOffset); index++ {
-		buf[index] = ExtismLoadU8(offset + ExtismPointer(index))
-	}
-}
-
-func Store(offset ExtismPointer, buf []byte) {
-	length := len(buf)
-	chunkCount := length >> 3
-
-	for chunkIdx := 0; chunkIdx < chunkCount; chunkIdx++ {
-		i := chunkIdx << 3
-		x := binary.LittleEndian.Uint64(buf[i : i+8])
-		ExtismStoreU64(offset+ExtismPointer(i), x)
-	}
-
-	remainder := length & 7
-	remainderOffset := chunkCount << 3
-	for index := remainderOffset; index < (remainder + remainderOffset); index++ {
-		ExtismStoreU8(offset+ExtismPointer(index), buf[index])
-	}
-}
-
 func NewMemory(offset ExtismPointer, length uint64) Memory {
 	return Memory{
 		offset: offset,
@@ -74,6 +39,12 @@ func (m *Memory) Offset() uint64 {
 	return uint64(m.offset)
 }
 
+// View returns the host memory block as a slice sharing its bytes, when the plugin and the host
+// share memory (see Shared). Otherwise, it returns false and the block must be copied with Load.
+func (m *Memory) View() ([]byte, bool) {
+	return view(m.offset, m.length)
+}
+
 // ReadBytes returns the host memory block as a slice of bytes.
 func (m *Memory) ReadBytes() []byte {
 	buff := make([]byte, m.length)
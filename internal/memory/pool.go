@@ -0,0 +1,85 @@
+package memory
+
+import "sync"
+
+// Pool keeps freed host memory blocks for reuse, saving the `alloc` and `free` host calls of
+// short-lived blocks.
+//
+// The host knows the length of a block from its offset, and uses it as the length of the data
+// (e.g. of a var key), so a block can only be reused for data of exactly the same length: the
+// size classes of a Pool are exact lengths. Blocks longer than its maximum length are not pooled.
+//
+// A block must only be returned to a Pool once the host no longer uses it. Blocks passed to the
+// host for keeping, such as var values or the output set with `output_set`, must not be pooled.
+//
+// The host resets its memory at the start of every call, after which pooled offsets may belong
+// to other blocks. A Pool must therefore be drained before the export returns, and never be kept
+// across calls.
+//
+// A Pool is safe for concurrent use.
+type Pool struct {
+	mu          sync.Mutex
+	maxLength   uint64
+	maxPerClass int
+	free        map[uint64][]ExtismPointer
+}
+
+// NewPool returns a `Pool` keeping at most `maxPerClass` freed blocks of each length up to
+// `maxLength` bytes.
+func NewPool(maxLength uint64, maxPerClass int) *Pool {
+	return &Pool{
+		maxLength:   maxLength,
+		maxPerClass: maxPerClass,
+		free:        map[uint64][]ExtismPointer{},
+	}
+}
+
+// Allocate returns a block of `length` uninitialized bytes, reusing a pooled block if possible.
+func (p *Pool) Allocate(length int) Memory {
+	clength := uint64(length)
+	p.mu.Lock()
+	if blocks := p.free[clength]; len(blocks) > 0 {
+		offset := blocks[len(blocks)-1]
+		p.free[clength] = blocks[:len(blocks)-1]
+		p.mu.Unlock()
+		return NewMemory(offset, clength)
+	}
+	p.mu.Unlock()
+	return Allocate(length)
+}
+
+// AllocateBytes returns a block holding `data`, reusing a pooled block if possible.
+func (p *Pool) AllocateBytes(data []byte) Memory {
+	mem := p.Allocate(len(data))
+	mem.Store(data)
+	return mem
+}
+
+// Free returns `mem` to the pool, or frees it on the host if it can't be pooled.
+func (p *Pool) Free(mem Memory) {
+	if mem.offset == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	if mem.length == 0 || mem.length > p.maxLength || len(p.free[mem.length]) >= p.maxPerClass {
+		p.mu.Unlock()
+		mem.Free()
+		return
+	}
+	p.free[mem.length] = append(p.free[mem.length], mem.offset)
+	p.mu.Unlock()
+}
+
+// Drain frees all the pooled blocks on the host.
+func (p *Pool) Drain() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for length, blocks := range p.free {
+		for _, offset := range blocks {
+			ExtismFree(offset)
+		}
+		delete(p.free, length)
+	}
+}
@@ -0,0 +1,38 @@
+package memory
+
+import "encoding/binary"
+
+// loadWords copies the host memory at `offset` into `buf`, 8 bytes per host call.
+func loadWords(offset ExtismPointer, buf []byte) {
+	length := len(buf)
+	chunkCount := length >> 3
+
+	for chunkIdx := 0; chunkIdx < chunkCount; chunkIdx++ {
+		i := chunkIdx << 3
+		binary.LittleEndian.PutUint64(buf[i:i+8], ExtismLoadU64(offset+ExtismPointer(i)))
+	}
+
+	remainder := length & 7
+	remainderOffset := chunkCount << 3
+	for index := remainderOffset; index < (remainder + remainderOffset); index++ {
+		buf[index] = ExtismLoadU8(offset + ExtismPointer(index))
+	}
+}
+
+// storeWords copies `buf` into the host memory at `offset`, 8 bytes per host call.
+func storeWords(offset ExtismPointer, buf []byte) {
+	length := len(buf)
+	chunkCount := length >> 3
+
+	for chunkIdx := 0; chunkIdx < chunkCount; chunkIdx++ {
+		i := chunkIdx << 3
+		x := binary.LittleEndian.Uint64(buf[i : i+8])
+		ExtismStoreU64(offset+ExtismPointer(i), x)
+	}
+
+	remainder := length & 7
+	remainderOffset := chunkCount << 3
+	for index := remainderOffset; index < (remainder + remainderOffset); index++ {
+		ExtismStoreU8(offset+ExtismPointer(index), buf[index])
+	}
+}
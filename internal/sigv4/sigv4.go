@@ -0,0 +1,227 @@
+// Package sigv4 signs HTTP requests with AWS Signature Version 4, for package
+// github.com/extism/go-pdk/aws and the SigV4 transport of package github.com/extism/go-pdk/http.
+//
+// The canonical request follows the AWS SigV4 test suite: the path is normalized and encoded twice
+// (except for S3, where it is encoded once as is), query parameters are sorted, and every header of
+// the request is signed except those that proxies and clients commonly rewrite.
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm  = "AWS4-HMAC-SHA256"
+	timeFormat = "20060102T150405Z"
+)
+
+// Credentials are the AWS credentials signing requests.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	// SessionToken is set for temporary credentials, and sent in the X-Amz-Security-Token header.
+	SessionToken string
+}
+
+// Request is the part of an HTTP request covered by its signature.
+type Request struct {
+	Method string
+	URL    *url.URL
+	Header map[string][]string
+	Body   []byte
+}
+
+// ignored are the headers left out of signatures.
+var ignored = map[string]bool{
+	"authorization":   true,
+	"user-agent":      true,
+	"x-amzn-trace-id": true,
+	"expect":          true,
+}
+
+// Sign returns the headers to set on `req` to sign it with `creds` for `service` in `region`:
+// Authorization, and X-Amz-Date (unless `req` already has one, otherwise `now` is used),
+// X-Amz-Security-Token with temporary credentials and X-Amz-Content-Sha256 for S3.
+func Sign(req *Request, creds Credentials, region, service string, now time.Time) map[string]string {
+	header := map[string][]string{}
+	for name, values := range req.Header {
+		if name = strings.ToLower(name); !ignored[name] {
+			header[name] = append(header[name], values...)
+		}
+	}
+	added := map[string]string{}
+	set := func(name, value string) {
+		added[name] = value
+		header[strings.ToLower(name)] = []string{value}
+	}
+	if _, ok := header["host"]; !ok {
+		header["host"] = []string{host(req.URL)}
+	}
+
+	date := ""
+	if values := header["x-amz-date"]; len(values) > 0 && len(values[0]) == len(timeFormat) {
+		date = values[0]
+	} else {
+		date = now.UTC().Format(timeFormat)
+		set("X-Amz-Date", date)
+	}
+	if creds.SessionToken != "" {
+		set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	payloadHash := hashHex(req.Body)
+	if service == "s3" {
+		set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	canonical.WriteString(req.Method + "\n")
+	canonical.WriteString(canonicalPath(req.URL, service) + "\n")
+	canonical.WriteString(canonicalQuery(req.URL) + "\n")
+	for _, name := range names {
+		canonical.WriteString(name + ":" + canonicalValue(header[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	canonical.WriteString("\n" + signedHeaders + "\n" + payloadHash)
+
+	day := date[:8]
+	scope := day + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := algorithm + "\n" + date + "\n" + scope + "\n" + hashHex([]byte(canonical.String()))
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	added["Authorization"] = algorithm + " Credential=" + creds.AccessKeyID + "/" + scope +
+		", SignedHeaders=" + signedHeaders + ", Signature=" + signature
+	return added
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// host returns the host of `u`, without the default port of its scheme.
+func host(u *url.URL) string {
+	h := u.Host
+	switch {
+	case u.Scheme == "http" && strings.HasSuffix(h, ":80"):
+		return strings.TrimSuffix(h, ":80")
+	case u.Scheme == "https" && strings.HasSuffix(h, ":443"):
+		return strings.TrimSuffix(h, ":443")
+	}
+	return h
+}
+
+// canonicalPath returns the path of `u` with each segment URI-encoded twice, as AWS services but
+// S3 expect, after removing its dot segments and duplicate slashes. S3 takes object keys as they
+// are, encoded once. The segments are those of the escaped path, so that an encoded slash doesn't
+// split one.
+func canonicalPath(u *url.URL, service string) string {
+	p := u.EscapedPath()
+	if p == "" {
+		return "/"
+	}
+	if service != "s3" {
+		trailing := strings.HasSuffix(p, "/")
+		p = path.Clean("/" + p)
+		if trailing && p != "/" {
+			p += "/"
+		}
+	}
+
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segment = encode(segment)
+		if service != "s3" {
+			segment = encode(segment)
+		}
+		segments[i] = segment
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the query parameters of `u`, encoded and sorted by name, then value.
+func canonicalQuery(u *url.URL) string {
+	if u.RawQuery == "" {
+		return ""
+	}
+	var params []string
+	for _, param := range strings.Split(u.RawQuery, "&") {
+		if param == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(param, "=")
+		params = append(params, encode(unescape(name))+"="+encode(unescape(value)))
+	}
+	sort.Slice(params, func(i, j int) bool {
+		ni, vi, _ := strings.Cut(params[i], "=")
+		nj, vj, _ := strings.Cut(params[j], "=")
+		if ni != nj {
+			return ni < nj
+		}
+		return vi < vj
+	})
+	return strings.Join(params, "&")
+}
+
+func unescape(s string) string {
+	if unescaped, err := url.QueryUnescape(s); err == nil {
+		return unescaped
+	}
+	return s
+}
+
+// canonicalValue joins the values of a header with commas, trimming them and collapsing their
+// sequences of spaces.
+func canonicalValue(values []string) string {
+	trimmed := make([]string, len(values))
+	for i, value := range values {
+		trimmed[i] = strings.Join(strings.Fields(value), " ")
+	}
+	return strings.Join(trimmed, ",")
+}
+
+const upperHex = "0123456789ABCDEF"
+
+// encode percent-encodes every byte of `s` but the unreserved characters of RFC 3986.
+func encode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(upperHex[c>>4])
+			b.WriteByte(upperHex[c&0xf])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,26 @@
+//go:build !nojson
+
+package pdk
+
+import "encoding/json"
+
+// marshalJSON and unmarshalJSON back the JSON helpers of the PDK. Building with the `nojson` tag
+// replaces them with versions that don't depend on encoding/json (see json_nojson.go).
+//
+// Values implementing json.Marshaler or json.Unmarshaler, such as types with methods generated by
+// pdk-jsongen, are encoded and decoded by their methods directly, skipping the reflection and
+// validation passes of encoding/json.
+
+func marshalJSON(v any) ([]byte, error) {
+	if m, ok := v.(json.Marshaler); ok {
+		return m.MarshalJSON()
+	}
+	return json.Marshal(v)
+}
+
+func unmarshalJSON(data []byte, v any) error {
+	if u, ok := v.(json.Unmarshaler); ok {
+		return u.UnmarshalJSON(data)
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,36 @@
+//go:build nojson
+
+package pdk
+
+import "errors"
+
+// Building with the `nojson` tag keeps encoding/json, and the reflection it relies on, out of the
+// plugin. The JSON helpers of the PDK then only handle values implementing their own JSON
+// encoding, with the methods of json.Marshaler and json.Unmarshaler (e.g. generated by
+// pdk-jsongen), and fail for any other value.
+
+// ErrNoJSON is returned by the JSON helpers for values they can't encode or decode when the PDK
+// is built with the `nojson` tag.
+var ErrNoJSON = errors.New("built with the nojson tag: JSON values must implement MarshalJSON and UnmarshalJSON")
+
+type jsonMarshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+type jsonUnmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+func marshalJSON(v any) ([]byte, error) {
+	if m, ok := v.(jsonMarshaler); ok {
+		return m.MarshalJSON()
+	}
+	return nil, ErrNoJSON
+}
+
+func unmarshalJSON(data []byte, v any) error {
+	if u, ok := v.(jsonUnmarshaler); ok {
+		return u.UnmarshalJSON(data)
+	}
+	return ErrNoJSON
+}
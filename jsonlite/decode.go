@@ -0,0 +1,498 @@
+package jsonlite
+
+import (
+	"bytes"
+	"encoding/base64"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// SyntaxError describes invalid JSON, or JSON not matching the decoded type.
+type SyntaxError struct {
+	Message string
+	// Offset is where the error occurred, in bytes from the start of the data.
+	Offset int
+}
+
+func (e *SyntaxError) Error() string {
+	return "jsonlite: " + e.Message + " at offset " + strconv.Itoa(e.Offset)
+}
+
+// Decoder reads a JSON value.
+type Decoder struct {
+	data []byte
+	pos  int
+}
+
+// NewDecoder returns a `Decoder` reading `data`.
+func NewDecoder(data []byte) *Decoder {
+	return &Decoder{data: data}
+}
+
+func (d *Decoder) fail(message string) error {
+	return &SyntaxError{Message: message, Offset: d.pos}
+}
+
+func (d *Decoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the first byte of the next value, or 0 at the end of the data.
+func (d *Decoder) peek() byte {
+	d.skipSpace()
+	if d.pos < len(d.data) {
+		return d.data[d.pos]
+	}
+	return 0
+}
+
+func (d *Decoder) consume(c byte) bool {
+	if d.peek() == c {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+func (d *Decoder) literal(s string) bool {
+	d.skipSpace()
+	if bytes.HasPrefix(d.data[d.pos:], []byte(s)) {
+		d.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// End checks that nothing but whitespace follows the decoded value.
+func (d *Decoder) End() error {
+	d.skipSpace()
+	if d.pos != len(d.data) {
+		return d.fail("invalid data after top-level value")
+	}
+	return nil
+}
+
+// Null reads a null value if it comes next, and reports whether it did.
+func (d *Decoder) Null() bool {
+	return d.literal("null")
+}
+
+// Object reads an object, calling `field` with the key of each of its fields, which must read the
+// field value (or skip it).
+func (d *Decoder) Object(field func(key string) error) error {
+	if !d.consume('{') {
+		return d.fail("expected object")
+	}
+	if d.consume('}') {
+		return nil
+	}
+	for {
+		key, err := d.string()
+		if err != nil {
+			return err
+		}
+		if !d.consume(':') {
+			return d.fail("expected ':' after object key")
+		}
+		if err := field(key); err != nil {
+			return err
+		}
+		if d.consume('}') {
+			return nil
+		}
+		if !d.consume(',') {
+			return d.fail("expected ',' or '}' after object field")
+		}
+	}
+}
+
+// Array reads an array, calling `item` for each of its items, which must read the item value (or
+// skip it).
+func (d *Decoder) Array(item func() error) error {
+	if !d.consume('[') {
+		return d.fail("expected array")
+	}
+	if d.consume(']') {
+		return nil
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if d.consume(']') {
+			return nil
+		}
+		if !d.consume(',') {
+			return d.fail("expected ',' or ']' after array item")
+		}
+	}
+}
+
+// Skip reads the next value, whatever it is.
+func (d *Decoder) Skip() error {
+	switch d.peek() {
+	case '{':
+		return d.Object(func(string) error { return d.Skip() })
+	case '[':
+		return d.Array(d.Skip)
+	case '"':
+		_, err := d.string()
+		return err
+	case 't', 'f':
+		_, err := d.bool()
+		return err
+	case 'n':
+		if !d.Null() {
+			return d.fail("invalid literal")
+		}
+		return nil
+	default:
+		_, err := d.number()
+		return err
+	}
+}
+
+// Raw reads the next value and returns its JSON encoding.
+func (d *Decoder) Raw() ([]byte, error) {
+	d.skipSpace()
+	start := d.pos
+	if err := d.Skip(); err != nil {
+		return nil, err
+	}
+	return d.data[start:d.pos], nil
+}
+
+// Unmarshaler reads the next value with the UnmarshalJSON method of `u`.
+func (d *Decoder) Unmarshaler(u Unmarshaler) error {
+	data, err := d.Raw()
+	if err != nil {
+		return err
+	}
+	return u.UnmarshalJSON(data)
+}
+
+func (d *Decoder) string() (string, error) {
+	if !d.consume('"') {
+		return "", d.fail("expected string")
+	}
+
+	var buf []byte
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return string(buf), nil
+		case c < 0x20:
+			return "", d.fail("invalid control character in string")
+		case c != '\\':
+			buf = append(buf, c)
+			d.pos++
+			continue
+		}
+
+		d.pos++
+		if d.pos >= len(d.data) {
+			break
+		}
+		c = d.data[d.pos]
+		d.pos++
+		switch c {
+		case '"', '\\', '/':
+			buf = append(buf, c)
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			r, ok := d.hex4()
+			if !ok {
+				return "", d.fail("invalid unicode escape")
+			}
+			if utf16.IsSurrogate(r) {
+				// a surrogate pair is encoded as two escapes
+				r2 := utf8.RuneError
+				if d.pos+1 < len(d.data) && d.data[d.pos] == '\\' && d.data[d.pos+1] == 'u' {
+					d.pos += 2
+					if low, ok := d.hex4(); ok {
+						r2 = low
+					}
+				}
+				r = utf16.DecodeRune(r, r2)
+			}
+			buf = utf8.AppendRune(buf, r)
+		default:
+			return "", d.fail("invalid escape in string")
+		}
+	}
+	return "", d.fail("unterminated string")
+}
+
+func (d *Decoder) hex4() (rune, bool) {
+	if d.pos+4 > len(d.data) {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(string(d.data[d.pos:d.pos+4]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	d.pos += 4
+	return rune(n), true
+}
+
+func (d *Decoder) bool() (bool, error) {
+	switch {
+	case d.literal("true"):
+		return true, nil
+	case d.literal("false"):
+		return false, nil
+	}
+	return false, d.fail("expected boolean")
+}
+
+// number reads a number, returning its text.
+func (d *Decoder) number() (string, error) {
+	d.skipSpace()
+	start := d.pos
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		if (c < '0' || c > '9') && c != '-' && c != '+' && c != '.' && c != 'e' && c != 'E' {
+			break
+		}
+		d.pos++
+	}
+	text := string(d.data[start:d.pos])
+	if !validNumber(text) {
+		d.pos = start
+		return "", d.fail("expected number")
+	}
+	return text, nil
+}
+
+// validNumber reports whether `s` follows the JSON number grammar.
+func validNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	switch {
+	case i < len(s) && s[i] == '0':
+		i++
+	case i < len(s) && s[i] >= '1' && s[i] <= '9':
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	default:
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if i == len(s) || s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i == len(s) || s[i] < '0' || s[i] > '9' {
+			return false
+		}
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	return i == len(s)
+}
+
+// The functions below read the next value into `p`, leaving it unchanged if the value is null.
+
+func String[T ~string](d *Decoder, p *T) error {
+	if d.Null() {
+		return nil
+	}
+	s, err := d.string()
+	if err != nil {
+		return err
+	}
+	*p = T(s)
+	return nil
+}
+
+func Bool[T ~bool](d *Decoder, p *T) error {
+	if d.Null() {
+		return nil
+	}
+	b, err := d.bool()
+	if err != nil {
+		return err
+	}
+	*p = T(b)
+	return nil
+}
+
+func Int[T ~int | ~int8 | ~int16 | ~int32 | ~int64](d *Decoder, p *T) error {
+	if d.Null() {
+		return nil
+	}
+	start := d.pos
+	text, err := d.number()
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil || int64(T(n)) != n {
+		d.pos = start
+		return d.fail("cannot decode number " + text + " into an integer of this size")
+	}
+	*p = T(n)
+	return nil
+}
+
+func Uint[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr](d *Decoder, p *T) error {
+	if d.Null() {
+		return nil
+	}
+	start := d.pos
+	text, err := d.number()
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseUint(text, 10, 64)
+	if err != nil || uint64(T(n)) != n {
+		d.pos = start
+		return d.fail("cannot decode number " + text + " into an unsigned integer of this size")
+	}
+	*p = T(n)
+	return nil
+}
+
+func Float[T ~float32 | ~float64](d *Decoder, p *T) error {
+	if d.Null() {
+		return nil
+	}
+	start := d.pos
+	text, err := d.number()
+	if err != nil {
+		return err
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsInf(float64(T(f)), 0) {
+		d.pos = start
+		return d.fail("cannot decode number " + text + " into a float of this size")
+	}
+	*p = T(f)
+	return nil
+}
+
+// Bytes reads a base64 string into `p`, like encoding/json does for byte slices. null sets it to
+// nil.
+func Bytes[T ~[]byte](d *Decoder, p *T) error {
+	if d.Null() {
+		*p = nil
+		return nil
+	}
+	start := d.pos
+	s, err := d.string()
+	if err != nil {
+		return err
+	}
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		d.pos = start
+		return d.fail("invalid base64 string")
+	}
+	*p = T(b)
+	return nil
+}
+
+// Pointer reads the next value with `decode` into the value `p` points to, allocating it if
+// needed. null sets `p` to nil.
+func Pointer[T any](d *Decoder, p **T, decode func(*T) error) error {
+	if d.Null() {
+		*p = nil
+		return nil
+	}
+	if *p == nil {
+		*p = new(T)
+	}
+	return decode(*p)
+}
+
+// Slice reads an array into `p`, decoding each item with `decode`. null sets `p` to nil.
+func Slice[S ~[]E, E any](d *Decoder, p *S, decode func(*E) error) error {
+	if d.Null() {
+		*p = nil
+		return nil
+	}
+	s := S{}
+	err := d.Array(func() error {
+		var item E
+		if err := decode(&item); err != nil {
+			return err
+		}
+		s = append(s, item)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	*p = s
+	return nil
+}
+
+// Map reads an object into `p`, decoding each field value with `decode`. null sets `p` to nil.
+func Map[M ~map[K]V, K ~string, V any](d *Decoder, p *M, decode func(*V) error) error {
+	if d.Null() {
+		*p = nil
+		return nil
+	}
+	if *p == nil {
+		*p = M{}
+	}
+	m := *p
+	return d.Object(func(key string) error {
+		var item V
+		if err := decode(&item); err != nil {
+			return err
+		}
+		m[K(key)] = item
+		return nil
+	})
+}
+
+// Field returns the field name among `names` matching `key`, preferring an exact match to a
+// case-insensitive one like encoding/json does, or `key` if none does.
+func Field(key string, names ...string) string {
+	for _, name := range names {
+		if name == key {
+			return name
+		}
+	}
+	for _, name := range names {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+	}
+	return key
+}
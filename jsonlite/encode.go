@@ -0,0 +1,253 @@
+// Package jsonlite encodes and decodes JSON without reflection, for plugins that want to keep
+// encoding/json out of their Wasm module.
+//
+// It is the runtime of the MarshalJSON and UnmarshalJSON methods generated by the pdk-jsongen
+// command, but can also be used by hand. An `Encoder` writes values in order:
+//
+//	e := jsonlite.NewEncoder()
+//	e.BeginObject()
+//	e.Key("name")
+//	e.String(v.Name)
+//	e.EndObject()
+//	return e.Bytes()
+//
+// while a `Decoder` reads them, calling a function for each object field or array item:
+//
+//	d := jsonlite.NewDecoder(data)
+//	err := d.Object(func(key string) error {
+//		switch key {
+//		case "name":
+//			return jsonlite.String(d, &v.Name)
+//		}
+//		return d.Skip()
+//	})
+//
+// Values are encoded like encoding/json does, and decoding follows its rules too: null leaves the
+// decoded value unchanged (or sets pointers, slices and maps to nil), and unknown object keys are
+// skipped.
+package jsonlite
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Marshaler is implemented by values encoding themselves as JSON, like json.Marshaler.
+type Marshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// Unmarshaler is implemented by values decoding themselves from JSON, like json.Unmarshaler.
+type Unmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+// Encoder writes a JSON value, separating object fields and array items as needed.
+type Encoder struct {
+	buf []byte
+	err error
+	// started holds, for each open object or array, whether a field or item was written to it.
+	started  []bool
+	afterKey bool
+}
+
+// NewEncoder returns an empty `Encoder`.
+func NewEncoder() *Encoder {
+	return &Encoder{buf: make([]byte, 0, 64)}
+}
+
+// Bytes returns the encoded JSON, or the first error met while encoding it.
+func (e *Encoder) Bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// separate writes the separator due before a key or value.
+func (e *Encoder) separate() {
+	if e.afterKey {
+		e.afterKey = false
+		return
+	}
+	if n := len(e.started); n > 0 {
+		if e.started[n-1] {
+			e.buf = append(e.buf, ',')
+		}
+		e.started[n-1] = true
+	}
+}
+
+func (e *Encoder) BeginObject() {
+	e.separate()
+	e.buf = append(e.buf, '{')
+	e.started = append(e.started, false)
+}
+
+func (e *Encoder) EndObject() {
+	e.started = e.started[:len(e.started)-1]
+	e.buf = append(e.buf, '}')
+}
+
+func (e *Encoder) BeginArray() {
+	e.separate()
+	e.buf = append(e.buf, '[')
+	e.started = append(e.started, false)
+}
+
+func (e *Encoder) EndArray() {
+	e.started = e.started[:len(e.started)-1]
+	e.buf = append(e.buf, ']')
+}
+
+// Key writes the key of the next object field.
+func (e *Encoder) Key(key string) {
+	e.separate()
+	e.buf = appendString(e.buf, key)
+	e.buf = append(e.buf, ':')
+	e.afterKey = true
+}
+
+func (e *Encoder) Null() {
+	e.separate()
+	e.buf = append(e.buf, "null"...)
+}
+
+func (e *Encoder) Bool(v bool) {
+	e.separate()
+	e.buf = strconv.AppendBool(e.buf, v)
+}
+
+func (e *Encoder) String(v string) {
+	e.separate()
+	e.buf = appendString(e.buf, v)
+}
+
+func (e *Encoder) Int(v int64) {
+	e.separate()
+	e.buf = strconv.AppendInt(e.buf, v, 10)
+}
+
+func (e *Encoder) Uint(v uint64) {
+	e.separate()
+	e.buf = strconv.AppendUint(e.buf, v, 10)
+}
+
+// Float writes `v`, of a float type of size `bits` (32 or 64), formatted like encoding/json does.
+// NaN and infinite values can't be encoded.
+func (e *Encoder) Float(v float64, bits int) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		e.fail(errors.New("jsonlite: unsupported value: " + strconv.FormatFloat(v, 'g', -1, bits)))
+		return
+	}
+	e.separate()
+
+	format := byte('f')
+	if abs := math.Abs(v); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	e.buf = strconv.AppendFloat(e.buf, v, format, -1, bits)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(e.buf)
+		if n >= 4 && e.buf[n-4] == 'e' && e.buf[n-3] == '-' && e.buf[n-2] == '0' {
+			e.buf[n-2] = e.buf[n-1]
+			e.buf = e.buf[:n-1]
+		}
+	}
+}
+
+// ByteSlice writes `v` as a base64 string, or null if it is nil.
+func (e *Encoder) ByteSlice(v []byte) {
+	if v == nil {
+		e.Null()
+		return
+	}
+	e.separate()
+	e.buf = append(e.buf, '"')
+	e.buf = append(e.buf, base64.StdEncoding.EncodeToString(v)...)
+	e.buf = append(e.buf, '"')
+}
+
+// Raw writes `data`, an already encoded JSON value.
+func (e *Encoder) Raw(data []byte) {
+	e.separate()
+	e.buf = append(e.buf, data...)
+}
+
+// Marshaler writes the JSON encoding of `m`.
+func (e *Encoder) Marshaler(m Marshaler) {
+	data, err := m.MarshalJSON()
+	if err != nil {
+		e.fail(err)
+		return
+	}
+	e.Raw(data)
+}
+
+func (e *Encoder) fail(err error) {
+	if e.err == nil {
+		e.err = err
+	}
+}
+
+// SortedKeys returns the keys of `m` in order, which encoding/json writes map fields in.
+func SortedKeys[M ~map[K]V, K ~string, V any](m M) []K {
+	keys := make([]K, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends `s` as a JSON string, escaped like encoding/json does: invalid UTF-8 is
+// replaced, and HTML characters as well as U+2028 and U+2029 are escaped.
+func appendString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			switch {
+			case c == '"' || c == '\\':
+				buf = append(buf, '\\', c)
+			case c == '\b':
+				buf = append(buf, '\\', 'b')
+			case c == '\f':
+				buf = append(buf, '\\', 'f')
+			case c == '\n':
+				buf = append(buf, '\\', 'n')
+			case c == '\r':
+				buf = append(buf, '\\', 'r')
+			case c == '\t':
+				buf = append(buf, '\\', 't')
+			case c < 0x20 || c == '<' || c == '>' || c == '&':
+				buf = append(buf, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			default:
+				buf = append(buf, c)
+			}
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			buf = append(buf, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			buf = append(buf, '\\', 'u', '2', '0', '2', hex[r&0xf])
+		default:
+			buf = append(buf, s[i:i+size]...)
+		}
+		i += size
+	}
+	return append(buf, '"')
+}
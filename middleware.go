@@ -0,0 +1,88 @@
+package pdk
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// Logging returns a `Middleware` logging the size of the input before the call, and its return
+// code, output size and error after it, at the given log `level`.
+func Logging(level LogLevel) Middleware {
+	return func(next Handler) Handler {
+		return func(c *Call) {
+			Log(level, "call started with "+strconv.Itoa(len(c.Input))+" bytes of input")
+			next(c)
+
+			msg := "call finished with code " + strconv.Itoa(int(c.Code)) +
+				" and " + strconv.Itoa(len(c.Output)) + " bytes of output"
+			if c.Err != nil {
+				msg += ", error: " + c.Err.Error()
+			}
+			Log(level, msg)
+		}
+	}
+}
+
+// Timing returns a `Middleware` logging how long the call took, at the given log `level`.
+func Timing(level LogLevel) Middleware {
+	return func(next Handler) Handler {
+		return func(c *Call) {
+			start := time.Now()
+			next(c)
+			Log(level, "call took "+time.Since(start).String())
+		}
+	}
+}
+
+// Recover returns a `Middleware` turning a panic in the call into an error, so the host gets the
+// panic message instead of a trap.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(c *Call) {
+			defer func() {
+				if r := recover(); r != nil {
+					c.Output = nil
+					c.Err = panicError(r)
+					c.Code = 1
+				}
+			}()
+			next(c)
+		}
+	}
+}
+
+// panicError converts a recovered panic value into an error.
+func panicError(r any) error {
+	switch r := r.(type) {
+	case error:
+		return errors.New("panic: " + r.Error())
+	case string:
+		return errors.New("panic: " + r)
+	case interface{ String() string }:
+		return errors.New("panic: " + r.String())
+	default:
+		return errors.New("panic")
+	}
+}
+
+// ErrUnauthorized is the error set by the `APIKey` middleware when a call is rejected.
+var ErrUnauthorized = errors.New("unauthorized: invalid API key")
+
+// APIKey returns a `Middleware` rejecting calls whose API key, as returned by `key`, doesn't match
+// the config value `configKey`. Rejected calls, and every call if the config value is not set,
+// fail with `ErrUnauthorized` without running the handler.
+func APIKey(configKey string, key func(c *Call) string) Middleware {
+	return func(next Handler) Handler {
+		return func(c *Call) {
+			expected, ok := GetConfig(configKey)
+			if !ok || expected == "" || subtle.ConstantTimeCompare([]byte(expected), []byte(key(c))) != 1 {
+				c.Err = ErrUnauthorized
+				c.Code = 1
+				return
+			}
+			next(c)
+		}
+	}
+}
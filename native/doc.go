@@ -0,0 +1,31 @@
+// Package native runs a plugin as a native binary, against a local host, so it can be debugged
+// with the usual tools (e.g. Delve) without rebuilding Wasm and calling it through the extism
+// CLI.
+//
+// When building for any architecture but wasm, the PDK host functions are implemented by a local
+// host: the input comes from a flag, a file or stdin, the config from flags or a JSON file, vars
+// are persisted to a local JSON file between runs, logs are written to stderr and HTTP requests
+// are sent with net/http.
+//
+// A plugin whose main function registers its exports and calls pdk.Run, as required by the
+// standard Go compiler, runs natively as is. pdk.Run then configures the local host from the
+// command-line flags, calls the selected export, writes its output to stdout and its error to
+// stderr, saves the vars and exits with the export return code:
+//
+//	go run -tags std ./example/countvowels -export count_vowels -input "this is a test"
+//	dlv debug --build-flags="-tags std" ./example/countvowels -- -export count_vowels -input-file -
+//
+// Flags:
+//
+//	-input string         plugin input
+//	-input-file path      file to read the plugin input from, "-" for stdin
+//	-config key=value     config value, can be repeated
+//	-config-file path     JSON object of config values
+//	-vars path            JSON file the vars are persisted to between runs
+//	-log-level level      trace, debug, info, warn or error (default info)
+//	-export name          export to call, when several are registered
+//
+// Plugins can instead be configured programmatically with `Configure`, before calling pdk.Run.
+// Exports can also be called from tests with `Call`, which resets the plugin memory between calls
+// like an Extism host does.
+package native
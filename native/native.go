@@ -0,0 +1,38 @@
+//go:build !wasm
+
+package native
+
+import "github.com/extism/go-pdk/internal/host"
+
+// Options configures the local host.
+type Options = host.Options
+
+// Configure configures the local host with `opts`, loading the vars file if it exists, instead of
+// from the command-line flags. It must be called before pdk.Run.
+func Configure(opts Options) error {
+	return host.Configure(opts)
+}
+
+// ParseLogLevel returns the `Options.LogLevel` called `name`: trace, debug, info, warn or error.
+func ParseLogLevel(name string) (int32, error) {
+	return host.ParseLogLevel(name)
+}
+
+// Flush writes the output and error set by the plugin to stdout and stderr, and saves the vars,
+// for plugins that call their exports directly rather than through pdk.Run.
+func Flush() error {
+	return host.Flush()
+}
+
+// Call calls `fn` as an export with `input`, and returns the output and error it set along with
+// its return code. Like on an Extism host, the plugin memory, output and error are reset before
+// the call while the config and vars persist, so that tests can make several calls in a row:
+//
+//	native.Configure(native.Options{Config: map[string]string{"vowels": "aeiou"}})
+//	output, errMsg, code := native.Call([]byte("this is a test"), countVowels)
+func Call(input []byte, fn func() int32) (output []byte, errMsg string, code int32) {
+	host.Begin(input)
+	code = fn()
+	output, errMsg = host.Result()
+	return output, errMsg, code
+}
@@ -0,0 +1,62 @@
+package pdk
+
+// Param decodes the host memory block at `offset` as a `T`, for exported functions taking memory
+// offsets as parameters:
+//
+//	//go:wasmexport add
+//	func add(a, b uint64) uint64 {
+//		x, err := pdk.Param[int32](a)
+//		...
+//	}
+//
+// Strings and byte slices are read as-is, booleans from a single byte, fixed-size numbers in
+// little-endian (with `int` and `uint` taking 8 bytes) and any other type as JSON. A block whose
+// length does not match the size of a fixed-size type gives a *DecodeError, as does a missing
+// block (offset 0) for any type but strings and byte slices.
+func Param[T any](offset uint64) (T, error) {
+	var v T
+	mem := FindMemory(offset)
+	return v, decodeValue(mem.ReadBytes(), &v)
+}
+
+// ParamAs decodes the host memory block at `offset` as a `T`, using the codec registered for
+// `contentType` (see `RegisterCodec`).
+func ParamAs[T any](offset uint64, contentType string) (T, error) {
+	var v T
+	codec, err := codecFor(contentType)
+	if err != nil {
+		return v, err
+	}
+
+	mem := FindMemory(offset)
+	data := mem.ReadBytes()
+	if err := codec.Unmarshal(data, &v); err != nil {
+		return v, &DecodeError{Type: contentType, Length: len(data), Err: err}
+	}
+	return v, nil
+}
+
+// Result encodes `v` into a newly allocated host memory block and returns its offset, using the
+// encoding read by `Param`.
+func Result[T any](v T) (uint64, error) {
+	data, err := encodeValue(v)
+	if err != nil {
+		return 0, err
+	}
+	return ResultBytes(data), nil
+}
+
+// ResultAs encodes `v` into a newly allocated host memory block with the codec registered for
+// `contentType`, and returns its offset.
+func ResultAs[T any](v T, contentType string) (uint64, error) {
+	codec, err := codecFor(contentType)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return 0, err
+	}
+	return ResultBytes(data), nil
+}
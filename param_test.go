@@ -0,0 +1,135 @@
+//go:build !wasm
+
+package pdk
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/extism/go-pdk/native"
+)
+
+// blockOf returns the offset of a new block holding `data`.
+func blockOf(data []byte) uint64 {
+	mem := AllocateBytes(data)
+	return mem.Offset()
+}
+
+// checkParam checks that `v` survives a Result and Param round trip in a block of `size` bytes,
+// and that Param rejects blocks one byte shorter or longer, and the missing block at offset 0.
+func checkParam[T comparable](t *testing.T, v T, size int) {
+	t.Helper()
+
+	native.Call(nil, func() int32 {
+		offset, err := Result(v)
+		if err != nil {
+			t.Fatalf("Result(%v): %v", v, err)
+		}
+		if mem := FindMemory(offset); mem.Length() != uint64(size) {
+			t.Errorf("Result(%T) block of %d bytes, want %d", v, mem.Length(), size)
+		}
+		if got, err := Param[T](offset); err != nil || got != v {
+			t.Errorf("Param[%T] = %v, %v, want %v", v, got, err, v)
+		}
+
+		offsets := map[string]uint64{"missing": 0}
+		if size > 0 {
+			offsets["short"] = blockOf(make([]byte, size-1))
+		}
+		offsets["oversized"] = blockOf(make([]byte, size+1))
+		for name, offset := range offsets {
+			var decodeErr *DecodeError
+			if _, err := Param[T](offset); !errors.As(err, &decodeErr) {
+				t.Errorf("Param[%T] of a %s block: error %v, want a DecodeError", v, name, err)
+			}
+		}
+		return 0
+	})
+}
+
+func TestParamFixedSize(t *testing.T) {
+	checkParam(t, true, 1)
+	checkParam(t, int8(-8), 1)
+	checkParam(t, uint8(8), 1)
+	checkParam(t, int16(-1600), 2)
+	checkParam(t, uint16(1600), 2)
+	checkParam(t, int32(math.MinInt32), 4)
+	checkParam(t, uint32(math.MaxUint32), 4)
+	checkParam(t, int64(math.MinInt64), 8)
+	checkParam(t, uint64(math.MaxUint64), 8)
+	checkParam(t, -64, 8)
+	checkParam(t, uint(64), 8)
+	checkParam(t, float32(-1.5), 4)
+	checkParam(t, math.Pi, 8)
+}
+
+// pair encodes itself as JSON, so that the test runs with the nojson tag too.
+type pair struct {
+	A, B int
+}
+
+func (p pair) MarshalJSON() ([]byte, error) {
+	return []byte(`[` + strconv.Itoa(p.A) + `,` + strconv.Itoa(p.B) + `]`), nil
+}
+
+func (p *pair) UnmarshalJSON(data []byte) error {
+	a, b, ok := strings.Cut(strings.Trim(string(data), "[]"), ",")
+	if !ok {
+		return errors.New("expected a pair")
+	}
+	var err error
+	if p.A, err = strconv.Atoi(a); err == nil {
+		p.B, err = strconv.Atoi(b)
+	}
+	return err
+}
+
+func TestParamJSON(t *testing.T) {
+	native.Call(nil, func() int32 {
+		offset, err := Result(pair{1, 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, err := Param[pair](offset); err != nil || got != (pair{1, 2}) {
+			t.Errorf("Param[pair] = %v, %v, want {1 2}", got, err)
+		}
+
+		for name, offset := range map[string]uint64{
+			"missing": 0,
+			"empty":   blockOf(nil),
+			"invalid": blockOf([]byte("[1]")),
+		} {
+			var decodeErr *DecodeError
+			if _, err := Param[pair](offset); !errors.As(err, &decodeErr) {
+				t.Errorf("Param[pair] of a %s block: error %v, want a DecodeError", name, err)
+			}
+		}
+		return 0
+	})
+}
+
+func TestParamBytes(t *testing.T) {
+	native.Call(nil, func() int32 {
+		offset, _ := Result("text")
+		if got, err := Param[string](offset); err != nil || got != "text" {
+			t.Errorf("Param[string] = %q, %v, want text", got, err)
+		}
+		offset, _ = Result([]byte{0, 1})
+		if got, err := Param[[]byte](offset); err != nil || !reflect.DeepEqual(got, []byte{0, 1}) {
+			t.Errorf("Param[[]byte] = %v, %v, want [0 1]", got, err)
+		}
+
+		// a missing block is the empty string or slice
+		if got, err := Param[string](0); err != nil || got != "" {
+			t.Errorf("Param[string](0) = %q, %v, want an empty string", got, err)
+		}
+		if got, err := Param[[]byte](0); err != nil || len(got) != 0 {
+			t.Errorf("Param[[]byte](0) = %v, %v, want an empty slice", got, err)
+		}
+		return 0
+	})
+}
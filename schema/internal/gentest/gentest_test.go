@@ -0,0 +1,43 @@
+//go:build !wasm
+
+package gentest
+
+import (
+	"testing"
+
+	"github.com/extism/go-pdk/native"
+)
+
+// TestOutputEmpty sends a summary with nil slices and maps through the generated export, which
+// validates its output: Go encodes them as null, which stands for their empty value.
+func TestOutputEmpty(t *testing.T) {
+	for _, test := range []struct {
+		input, output string
+	}{
+		{"empty", `{"counts":null,"digest":null,"name":"empty","tags":null}`},
+		{"full", `{"counts":{"a":1},"digest":"AQ==","name":"full","note":"note","tags":["a"]}`},
+	} {
+		output, errMsg, code := native.Call([]byte(test.input), exportSummarize)
+		if code != 0 || string(output) != test.output {
+			t.Errorf("Summarize(%q) = %d, %s (%s), want %s", test.input, code, output, errMsg, test.output)
+		}
+	}
+}
+
+func TestValidateNull(t *testing.T) {
+	summary := xtpSchema.Components.Schemas["Summary"]
+	for _, test := range []struct {
+		data string
+		ok   bool
+	}{
+		{`{"counts":null,"digest":null,"name":"","tags":null}`, true},
+		{`{"counts":{},"digest":"","name":"","note":null,"tags":[]}`, true},
+		{`{"counts":null,"digest":null,"name":null,"tags":null}`, false},
+		{`{"counts":null,"digest":null,"name":"","tags":[null]}`, false},
+		{`null`, false},
+	} {
+		if err := xtpSchema.Validate(summary, []byte(test.data)); (err == nil) != test.ok {
+			t.Errorf("Validate(%s) = %v, want ok %v", test.data, err, test.ok)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Code generated by xtp-gen-go. DO NOT EDIT.
+
+package gentest
+
+import (
+	"github.com/extism/go-pdk"
+	"github.com/extism/go-pdk/schema"
+)
+
+type Summary struct {
+	Counts map[string]any `json:"counts"`
+	Digest []byte         `json:"digest"`
+	Name   string         `json:"name"`
+	Note   *string        `json:"note,omitempty"`
+	Tags   []string       `json:"tags"`
+}
+
+// exportSummarize is the "Summarize" export, calling Summarize.
+//
+//go:wasmexport Summarize
+func exportSummarize() int32 {
+	data, err := xtpSchema.Input("Summarize")
+	if err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+	input := string(data)
+
+	output, err := Summarize(input)
+	if err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+
+	if err := xtpSchema.OutputJSON("Summarize", output); err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+	return 0
+}
+
+// xtpSchema is the schema the exports validate their input and output against.
+var xtpSchema = &schema.Document{
+	Version: "v1-draft",
+	Exports: map[string]*schema.Function{
+		"Summarize": {
+			Input: &schema.Param{
+				Type: schema.Type{
+					Type: "string",
+				},
+				ContentType: "text/plain; charset=utf-8",
+			},
+			Output: &schema.Param{
+				Type: schema.Type{
+					Ref: "#/components/schemas/Summary",
+				},
+				ContentType: "application/json",
+			},
+		},
+	},
+	Components: schema.Components{
+		Schemas: map[string]*schema.Type{
+			"Summary": {
+				Properties: map[string]*schema.Type{
+					"counts": {
+						Type: "object",
+					},
+					"digest": {
+						Type: "buffer",
+					},
+					"name": {
+						Type: "string",
+					},
+					"note": {
+						Type:     "string",
+						Nullable: true,
+					},
+					"tags": {
+						Type: "array",
+						Items: &schema.Type{
+							Type: "string",
+						},
+					},
+				},
+			},
+		},
+	},
+}
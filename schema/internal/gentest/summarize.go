@@ -0,0 +1,18 @@
+// Package gentest tests the validation of the bindings generated by xtp-gen-go with -validate.
+package gentest
+
+//go:generate go run github.com/extism/go-pdk/cmd/xtp-gen-go -schema schema.yaml -o pdk.gen.go -package gentest -validate
+
+// Summarize returns a summary named after the input, leaving its other fields empty unless the
+// input is "full".
+func Summarize(input string) (Summary, error) {
+	summary := Summary{Name: input}
+	if input == "full" {
+		note := "note"
+		summary.Tags = []string{"a"}
+		summary.Counts = map[string]any{"a": 1}
+		summary.Digest = []byte{1}
+		summary.Note = &note
+	}
+	return summary, nil
+}
@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/extism/go-pdk"
+)
+
+// ValidateInput checks `data` against the input of `export`, according to its content type:
+// JSON inputs are validated against their type, text inputs must be valid UTF-8 (and one of the
+// enum values, if any), and binary inputs are accepted as-is.
+func (d *Document) ValidateInput(export string, data []byte) error {
+	fn, err := d.export(export)
+	if err != nil {
+		return err
+	}
+	return d.validateParam(fn.Input, data)
+}
+
+// ValidateOutput checks `data` against the output of `export`, like `ValidateInput` does.
+func (d *Document) ValidateOutput(export string, data []byte) error {
+	fn, err := d.export(export)
+	if err != nil {
+		return err
+	}
+	return d.validateParam(fn.Output, data)
+}
+
+// Input returns the plugin input after validating it against the input of `export`.
+func (d *Document) Input(export string) ([]byte, error) {
+	data := pdk.Input()
+	if err := d.ValidateInput(export, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// InputJSON validates the plugin input against the input of `export`, then unmarshals it into `v`.
+func (d *Document) InputJSON(export string, v any) error {
+	data, err := d.Input(export)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Output validates `data` against the output of `export`, then sends it to the host output.
+// Invalid data is not sent.
+func (d *Document) Output(export string, data []byte) error {
+	if err := d.ValidateOutput(export, data); err != nil {
+		return err
+	}
+	pdk.Output(data)
+	return nil
+}
+
+// OutputJSON marshals `v`, validates it against the output of `export`, then sends it to the host
+// output. Invalid data is not sent.
+func (d *Document) OutputJSON(export string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return d.Output(export, data)
+}
+
+func (d *Document) export(name string) (*Function, error) {
+	fn, ok := d.Exports[name]
+	if !ok || fn == nil {
+		return nil, fmt.Errorf("schema has no export %q", name)
+	}
+	return fn, nil
+}
+
+func (d *Document) validateParam(p *Param, data []byte) error {
+	if p == nil {
+		return nil
+	}
+
+	switch d.encodingOf(p) {
+	case encodingText:
+		return d.checkText(&p.Type, data)
+	case encodingBinary:
+		return nil
+	default:
+		return d.Validate(&p.Type, data)
+	}
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
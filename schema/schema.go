@@ -0,0 +1,112 @@
+// Package schema validates plugin inputs and outputs against an XTP schema, such as the
+// example-schema.yaml file at the root of this repository.
+//
+// A `Document` mirrors the structure of the schema. It can be written by hand, generated as Go
+// code by the xtp-gen-go command (with -validate), or decoded from the JSON form of the schema
+// with `Parse`.
+//
+// Object properties listed in `Required` must be present; when an object has no `Required` list,
+// every property that is not nullable is required instead. Only nullable values may be null, as
+// well as arrays, buffers and free-form objects, for which null is the empty value Go encodes nil
+// slices and maps as.
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const refPrefix = "#/components/schemas/"
+
+// Document is an XTP schema.
+type Document struct {
+	Version    string               `json:"version,omitempty"`
+	Exports    map[string]*Function `json:"exports,omitempty"`
+	Imports    map[string]*Function `json:"imports,omitempty"`
+	Components Components           `json:"components,omitempty"`
+}
+
+// Components holds the named schemas of a `Document`.
+type Components struct {
+	Schemas map[string]*Type `json:"schemas,omitempty"`
+}
+
+// Function is an export implemented by the plugin, or an import provided by the host.
+type Function struct {
+	Description string `json:"description,omitempty"`
+	Input       *Param `json:"input,omitempty"`
+	Output      *Param `json:"output,omitempty"`
+}
+
+// Param is the input or output of a `Function`.
+type Param struct {
+	Type
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// Type describes the values accepted by a schema.
+type Type struct {
+	Description string `json:"description,omitempty"`
+	// Type is one of "string", "integer", "number", "boolean", "array", "object" or "buffer".
+	// An empty Type accepts any value.
+	Type   string `json:"type,omitempty"`
+	Format string `json:"format,omitempty"`
+	// Ref references a component schema, as "#/components/schemas/Name".
+	Ref        string           `json:"$ref,omitempty"`
+	Nullable   bool             `json:"nullable,omitempty"`
+	Items      *Type            `json:"items,omitempty"`
+	Properties map[string]*Type `json:"properties,omitempty"`
+	Required   []string         `json:"required,omitempty"`
+	Enum       []string         `json:"enum,omitempty"`
+}
+
+// Parse decodes the JSON form of an XTP schema.
+func Parse(data []byte) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// resolve returns the component schema referenced by `ref`, or nil if there is none.
+func (d *Document) resolve(ref string) *Type {
+	name := strings.TrimPrefix(ref, refPrefix)
+	return d.Components.Schemas[name]
+}
+
+// encoding is how the value of a `Param` is transferred.
+type encoding int
+
+const (
+	encodingJSON encoding = iota
+	encodingText
+	encodingBinary
+)
+
+func (d *Document) encodingOf(p *Param) encoding {
+	mediaType, _, _ := strings.Cut(p.ContentType, ";")
+	switch strings.TrimSpace(mediaType) {
+	case "application/json":
+		return encodingJSON
+	case "text/plain":
+		return encodingText
+	case "application/x-binary", "application/octet-stream":
+		return encodingBinary
+	}
+
+	t := &p.Type
+	if t.Ref != "" {
+		if resolved := d.resolve(t.Ref); resolved != nil {
+			t = resolved
+		}
+	}
+	switch {
+	case t.Type == "buffer":
+		return encodingBinary
+	case t.Type == "string" && t.Format == "" && !t.Nullable:
+		return encodingText
+	default:
+		return encodingJSON
+	}
+}
@@ -0,0 +1,264 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Violation describes a value which does not match its schema.
+type Violation struct {
+	// Path locates the value, e.g. "$.items[2].name", "$" being the whole document.
+	Path    string
+	Message string
+}
+
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidationError is returned when a value does not match its schema, listing every violation.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.String()
+	}
+	return "schema validation failed: " + strings.Join(messages, "; ")
+}
+
+// Validate checks that the JSON `data` is a valid value of type `t`, returning a
+// *ValidationError if it is not.
+func (d *Document) Validate(t *Type, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return &ValidationError{Violations: []Violation{{Path: "$", Message: "invalid JSON: " + err.Error()}}}
+	}
+	if dec.More() {
+		return &ValidationError{Violations: []Violation{{Path: "$", Message: "invalid JSON: trailing data"}}}
+	}
+
+	v := validator{doc: d}
+	v.check("$", t, value)
+	return v.err()
+}
+
+// validator accumulates the violations found while walking a value.
+type validator struct {
+	doc        *Document
+	violations []Violation
+}
+
+func (v *validator) fail(path, message string) {
+	v.violations = append(v.violations, Violation{Path: path, Message: message})
+}
+
+func (v *validator) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return &ValidationError{Violations: v.violations}
+}
+
+func (v *validator) check(path string, t *Type, value any) {
+	nullable := t.Nullable
+	if t.Ref != "" {
+		resolved := v.doc.resolve(t.Ref)
+		if resolved == nil {
+			v.fail(path, "reference to unknown schema "+strconv.Quote(t.Ref))
+			return
+		}
+		t = resolved
+		nullable = nullable || t.Nullable
+	}
+
+	if value == nil {
+		if !nullable && !t.emptyNull() {
+			v.fail(path, "must not be null")
+		}
+		return
+	}
+
+	if len(t.Enum) > 0 {
+		s, ok := value.(string)
+		if !ok {
+			v.fail(path, "expected string")
+			return
+		}
+		if !contains(t.Enum, s) {
+			v.fail(path, strconv.Quote(s)+" is not one of "+strings.Join(t.Enum, ", "))
+		}
+		return
+	}
+
+	switch t.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.fail(path, "expected string")
+			return
+		}
+		v.checkFormat(path, t.Format, s)
+	case "buffer":
+		s, ok := value.(string)
+		if !ok {
+			v.fail(path, "expected base64 encoded buffer")
+			return
+		}
+		if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+			v.fail(path, "expected base64 encoded buffer")
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			v.fail(path, "expected integer")
+			return
+		}
+		i, err := strconv.ParseInt(n.String(), 10, 64)
+		if err != nil {
+			v.fail(path, "expected integer, got "+n.String())
+			return
+		}
+		if t.Format == "int32" && (i < math.MinInt32 || i > math.MaxInt32) {
+			v.fail(path, n.String()+" overflows int32")
+		}
+	case "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			v.fail(path, "expected number")
+			return
+		}
+		f, err := n.Float64()
+		if err != nil {
+			v.fail(path, "expected number, got "+n.String())
+			return
+		}
+		if t.Format == "float" && math.Abs(f) > math.MaxFloat32 {
+			v.fail(path, n.String()+" overflows float")
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, "expected boolean")
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			v.fail(path, "expected array")
+			return
+		}
+		if t.Items == nil {
+			return
+		}
+		for i, item := range items {
+			v.check(path+"["+strconv.Itoa(i)+"]", t.Items, item)
+		}
+	case "object":
+		v.checkObject(path, t, value)
+	case "":
+		if len(t.Properties) > 0 {
+			v.checkObject(path, t, value)
+		}
+	default:
+		v.fail(path, "unsupported schema type "+strconv.Quote(t.Type))
+	}
+}
+
+func (v *validator) checkObject(path string, t *Type, value any) {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		v.fail(path, "expected object")
+		return
+	}
+
+	for _, name := range sortedKeys(t.Properties) {
+		prop := t.Properties[name]
+		propPath := path + "." + name
+
+		propValue, present := obj[name]
+		if !present {
+			if t.requires(name) {
+				v.fail(propPath, "is required")
+			}
+			continue
+		}
+		v.check(propPath, prop, propValue)
+	}
+}
+
+func (v *validator) checkFormat(path, format, s string) {
+	var err error
+	switch format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339, s)
+	case "date":
+		_, err = time.Parse(time.DateOnly, s)
+	case "byte":
+		_, err = base64.StdEncoding.DecodeString(s)
+	default:
+		return
+	}
+	if err != nil {
+		v.fail(path, strconv.Quote(s)+" is not a valid "+format)
+	}
+}
+
+// emptyNull reports whether null stands for an empty value of type `t`: Go encodes nil slices and
+// maps, such as the array, buffer and free-form object fields of the types generated by
+// xtp-gen-go, as null.
+func (t *Type) emptyNull() bool {
+	switch t.Type {
+	case "array", "buffer":
+		return true
+	case "object":
+		return len(t.Properties) == 0
+	}
+	return false
+}
+
+// requires reports whether the object property `name` must be present.
+func (t *Type) requires(name string) bool {
+	if len(t.Required) > 0 {
+		return contains(t.Required, name)
+	}
+	prop := t.Properties[name]
+	return prop != nil && !prop.Nullable
+}
+
+// checkText validates a text/plain value of type `t`.
+func (d *Document) checkText(t *Type, data []byte) error {
+	v := validator{doc: d}
+	if !utf8.Valid(data) {
+		v.fail("$", "invalid UTF-8 text")
+		return v.err()
+	}
+
+	if t.Ref != "" {
+		if resolved := d.resolve(t.Ref); resolved != nil {
+			t = resolved
+		}
+	}
+	if len(t.Enum) > 0 && !contains(t.Enum, string(data)) {
+		v.fail("$", strconv.Quote(string(data))+" is not one of "+strings.Join(t.Enum, ", "))
+	}
+	return v.err()
+}
+
+func contains(values []string, s string) bool {
+	for _, value := range values {
+		if value == s {
+			return true
+		}
+	}
+	return false
+}
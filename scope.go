@@ -0,0 +1,62 @@
+package pdk
+
+// Allocator allocates host memory blocks that are freed when its `Scope` exits.
+type Allocator struct {
+	arena *Arena
+}
+
+// Scope calls `fn` with an `Allocator`, and frees all the memory allocated through it once `fn`
+// returns (or panics), except for the blocks passed to `Allocator.Keep`. It returns the error
+// returned by `fn`.
+//
+//	err := pdk.Scope(func(s *pdk.Allocator) error {
+//		arg := s.AllocateString("an argument")
+//		result := pdk.FindMemory(aHostFunc(arg.Offset()))
+//		s.Track(result)
+//		pdk.OutputMemory(s.Keep(s.AllocateBytes(result.ReadBytes())))
+//		return nil
+//	})
+func Scope(fn func(s *Allocator) error) error {
+	s := &Allocator{arena: NewArena()}
+	defer s.arena.Release()
+	return fn(s)
+}
+
+// Allocate allocates `length` uninitialized bytes.
+func (s *Allocator) Allocate(length int) Memory {
+	return s.arena.Allocate(length)
+}
+
+// AllocateBytes allocates and saves `data`.
+func (s *Allocator) AllocateBytes(data []byte) Memory {
+	return s.arena.AllocateBytes(data)
+}
+
+// AllocateString allocates and saves the UTF-8 string `data`.
+func (s *Allocator) AllocateString(data string) Memory {
+	return s.arena.AllocateBytes([]byte(data))
+}
+
+// AllocateJSON allocates and saves `v` encoded as JSON.
+func (s *Allocator) AllocateJSON(v any) (Memory, error) {
+	b, err := marshalJSON(v)
+	if err != nil {
+		return Memory{}, err
+	}
+	return s.arena.AllocateBytes(b), nil
+}
+
+// Track makes the scope free `mem`, a block allocated elsewhere (e.g. returned by a host
+// function), when it exits.
+func (s *Allocator) Track(mem Memory) Memory {
+	s.arena.Track(mem)
+	return mem
+}
+
+// Keep detaches `mem` from the scope, so it is not freed when the scope exits, and returns it.
+// Blocks handed over to the host, such as those passed to `OutputMemory` or stored by a host
+// function, must be kept.
+func (s *Allocator) Keep(mem Memory) Memory {
+	s.arena.Keep(mem)
+	return mem
+}
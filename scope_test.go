@@ -0,0 +1,124 @@
+//go:build !wasm
+
+package pdk
+
+import (
+	"testing"
+
+	"github.com/extism/go-pdk/native"
+)
+
+// The local host resets its memory at the start of every call, like an Extism host, so offsets
+// kept from a call may belong to other blocks in the next one.
+
+func TestHelpersAcrossCalls(t *testing.T) {
+	native.Configure(native.Options{Config: map[string]string{"abcd": "1", "efgh": "2"}})
+	defer native.Configure(native.Options{})
+
+	native.Call(nil, func() int32 {
+		GetConfig("abcd")
+		SetVar("wxyz", []byte("var"))
+		return 0
+	})
+
+	output, _, _ := native.Call(nil, func() int32 {
+		out := AllocateString("kept")
+		if value, _ := GetConfig("efgh"); value != "2" {
+			t.Errorf(`GetConfig("efgh") = %q, want "2"`, value)
+		}
+		if value := GetVar("wxyz"); string(value) != "var" {
+			t.Errorf(`GetVar("wxyz") = %q, want "var"`, value)
+		}
+		OutputMemory(out)
+		return 0
+	})
+	if string(output) != "kept" {
+		t.Errorf("output = %q, want kept", output)
+	}
+}
+
+func TestScopeAcrossCalls(t *testing.T) {
+	for _, want := range []string{"first", "other"} {
+		output, errMsg, _ := native.Call(nil, func() int32 {
+			err := Scope(func(s *Allocator) error {
+				s.AllocateString("tmp1")
+				out := s.Keep(s.AllocateString(want))
+				s.AllocateString("tmp2")
+				OutputMemory(out)
+				return nil
+			})
+			if err != nil {
+				SetError(err)
+				return 1
+			}
+			return 0
+		})
+		if string(output) != want {
+			t.Errorf("output = %q (%s), want %q", output, errMsg, want)
+		}
+	}
+}
+
+func TestArenaReset(t *testing.T) {
+	native.Call(nil, func() int32 {
+		a := NewArena()
+		defer a.Release()
+
+		first := a.AllocateBytes([]byte("12345678"))
+		a.Reset()
+		second := a.AllocateBytes([]byte("abcdefgh"))
+		if second.Offset() != first.Offset() {
+			t.Errorf("block not reused after Reset: offset %d, want %d", second.Offset(), first.Offset())
+		}
+		if got := second.ReadBytes(); string(got) != "abcdefgh" {
+			t.Errorf("reused block holds %q, want abcdefgh", got)
+		}
+		return 0
+	})
+}
+
+func TestHelpersPooled(t *testing.T) {
+	for _, pooled := range []bool{false, true} {
+		native.Call(nil, func() int32 {
+			helpers := func() int32 {
+				first := Allocate(1)
+				for i := 0; i < 100; i++ {
+					RemoveVar("abcd")
+				}
+				next := Allocate(1)
+				// with a pool, the key block is allocated once and reused
+				if grown := next.Offset() - first.Offset(); pooled != (grown < 100*8) {
+					t.Errorf("pooled %v: memory grew by %d bytes for 100 keys", pooled, grown)
+				}
+				return 0
+			}
+			if pooled {
+				return Pooled(helpers)
+			}
+			return helpers()
+		})
+	}
+	if callPool != nil {
+		t.Error("call pool kept after Pooled returned")
+	}
+}
+
+func TestServePooled(t *testing.T) {
+	native.Configure(native.Options{Config: map[string]string{"abcd": "1"}})
+	defer native.Configure(native.Options{})
+
+	// the key block pooled in the first call must not be reused in the second
+	for _, want := range []string{"first", "other"} {
+		output, errMsg, _ := native.Call([]byte(want), func() int32 {
+			return Serve(func(c *Call) {
+				if value, _ := GetConfig("abcd"); value != "1" {
+					t.Errorf(`GetConfig("abcd") = %q, want "1"`, value)
+				}
+				c.Output = c.Input
+			})
+		})
+		if string(output) != want {
+			t.Errorf("output = %q (%s), want %q", output, errMsg, want)
+		}
+	}
+}
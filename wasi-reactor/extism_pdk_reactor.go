@@ -1,9 +1,16 @@
 package reactor
 
+import (
+	pdk "github.com/extism/go-pdk"
+)
+
 //export __wasm_call_ctors
 func wasmCallCtors()
 
 //export _initialize
 func initialize() {
 	wasmCallCtors()
+	if err := runInit(); err != nil {
+		pdk.SetError(err)
+	}
 }
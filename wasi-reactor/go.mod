@@ -1,3 +1,5 @@
 module github.com/extism/go-pdk/wasi-reactor
 
 go 1.21.1
+
+require github.com/extism/go-pdk v1.1.3
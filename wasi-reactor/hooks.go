@@ -0,0 +1,89 @@
+package reactor
+
+import (
+	pdk "github.com/extism/go-pdk"
+)
+
+var hooks struct {
+	init   []func() error
+	before []func() error
+	after  []func(code int32)
+
+	initialized bool
+	initErr     error
+}
+
+// OnInit registers `fn` to run once, after the module is initialized and before any export wrapped
+// with `Call` runs. It is meant to be called from an `init` function, to load config or warm caches.
+//
+// Hooks run in registration order, from `_initialize` or, for modules initialized by the toolchain
+// (such as TinyGo's `-buildmode=c-shared` with `//go:wasmexport`), from the first `Call`. If a hook
+// returns an error, the remaining hooks are skipped, the error is reported to the host with
+// `pdk.SetError`, and every `Call` fails with it.
+func OnInit(fn func() error) {
+	hooks.init = append(hooks.init, fn)
+}
+
+// Before registers `fn` to run before each export wrapped with `Call`. If it returns an error, the
+// export is skipped and `Call` fails with that error.
+func Before(fn func() error) {
+	hooks.before = append(hooks.before, fn)
+}
+
+// After registers `fn` to run after each export wrapped with `Call`, with the export's return code.
+// It runs even if the export failed, e.g. to flush metrics.
+func After(fn func(code int32)) {
+	hooks.after = append(hooks.after, fn)
+}
+
+// Call runs `export` between the `Before` and `After` hooks, and returns its return code. Exports
+// opt into the hooks by wrapping their body:
+//
+//	//go:wasmexport greet
+//	func greet() int32 {
+//		return reactor.Call(func() int32 {
+//			pdk.OutputString("Hello, " + pdk.InputString() + "!")
+//			return 0
+//		})
+//	}
+func Call(export func() int32) int32 {
+	return pdk.Pooled(func() int32 {
+		code := run(export)
+		for _, fn := range hooks.after {
+			fn(code)
+		}
+		return code
+	})
+}
+
+func run(export func() int32) int32 {
+	if err := runInit(); err != nil {
+		pdk.SetError(err)
+		return 1
+	}
+
+	for _, fn := range hooks.before {
+		if err := fn(); err != nil {
+			pdk.SetError(err)
+			return 1
+		}
+	}
+
+	return export()
+}
+
+// runInit runs the `OnInit` hooks the first time it is called, and returns their error.
+func runInit() error {
+	if hooks.initialized {
+		return hooks.initErr
+	}
+	hooks.initialized = true
+
+	for _, fn := range hooks.init {
+		if err := fn(); err != nil {
+			hooks.initErr = err
+			break
+		}
+	}
+	return hooks.initErr
+}